@@ -0,0 +1,116 @@
+package gfx
+
+import (
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// AlphaMode describes how color is stored relative to alpha.
+type AlphaMode int
+
+const (
+	// AlphaStraight stores color independently of alpha. Filtering straight
+	// alpha textures causes dark fringes around transparent texels.
+	AlphaStraight AlphaMode = iota
+	// AlphaPremultiplied stores color already multiplied by alpha, which
+	// filters and blends correctly.
+	AlphaPremultiplied
+)
+
+// BlendFunc returns the source and destination factors for glBlendFunc that
+// implement "over" compositing for colors in this mode:
+//
+//	AlphaStraight:      gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA
+//	AlphaPremultiplied: gl.ONE,       gl.ONE_MINUS_SRC_ALPHA
+func (m AlphaMode) BlendFunc() (sfactor, dfactor uint32) {
+	if m == AlphaPremultiplied {
+		return gl.ONE, gl.ONE_MINUS_SRC_ALPHA
+	}
+	return gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA
+}
+
+// SetBlendFunc sets the blend function matching this mode (see BlendFunc).
+func (m AlphaMode) SetBlendFunc() {
+	gl.BlendFunc(m.BlendFunc())
+}
+
+// Premultiply multiplies the color of RGBA data by its alpha in place.
+func Premultiply(rgba []byte) {
+	for i := 0; i+3 < len(rgba); i += 4 {
+		a := uint32(rgba[i+3])
+		for c := 0; c < 3; c++ {
+			rgba[i+c] = byte((uint32(rgba[i+c])*a + 127) / 255)
+		}
+	}
+}
+
+// Unpremultiply divides the color of premultiplied RGBA data by its alpha in
+// place. Color is lost where alpha is zero.
+func Unpremultiply(rgba []byte) {
+	for i := 0; i+3 < len(rgba); i += 4 {
+		a := uint32(rgba[i+3])
+		if a == 0 || a == 255 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			v := (uint32(rgba[i+c])*255 + a/2) / a
+			if v > 255 {
+				v = 255
+			}
+			rgba[i+c] = byte(v)
+		}
+	}
+}
+
+// srgbDecode maps sRGB encoded bytes to linear values.
+var srgbDecode = func() (tab [256]float64) {
+	for i := range tab {
+		tab[i] = srgbToLinear(float64(i) / 255)
+	}
+	return tab
+}()
+
+// encodeSRGB converts a linear value to an sRGB encoded byte.
+func encodeSRGB(v float64) byte {
+	if v >= 1 {
+		return 255
+	}
+	return byte(linearToSRGB(v)*255 + 0.5)
+}
+
+// PremultiplySRGB multiplies the color of sRGB encoded RGBA data by its alpha
+// in place. The multiplication is done in linear space, which is what
+// sampling an sRGB texture expects.
+func PremultiplySRGB(rgba []byte) {
+	for i := 0; i+3 < len(rgba); i += 4 {
+		a := rgba[i+3]
+		if a == 255 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			rgba[i+c] = encodeSRGB(srgbDecode[rgba[i+c]] * float64(a) / 255)
+		}
+	}
+}
+
+// UnpremultiplySRGB reverses PremultiplySRGB in place. Color is lost where
+// alpha is zero.
+func UnpremultiplySRGB(rgba []byte) {
+	for i := 0; i+3 < len(rgba); i += 4 {
+		a := rgba[i+3]
+		if a == 0 || a == 255 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			rgba[i+c] = encodeSRGB(srgbDecode[rgba[i+c]] * 255 / float64(a))
+		}
+	}
+}
+
+// premultiply calls PremultiplySRGB for sRGB data and Premultiply otherwise.
+func premultiply(rgba []byte, srgb bool) {
+	if srgb {
+		PremultiplySRGB(rgba)
+	} else {
+		Premultiply(rgba)
+	}
+}
@@ -0,0 +1,356 @@
+package gfx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+// Animation is a sequence of equally sized frames with per-frame delays.
+type Animation struct {
+	// Frames holds every frame fully composited.
+	Frames []image.Image
+	// Delays holds how long each frame is shown.
+	Delays []time.Duration
+	// Plays is the number of times the animation plays, 0 meaning forever.
+	Plays int
+}
+
+// LoadAnimation decodes the GIF or APNG animation stored in fileName. A PNG
+// without animation decodes to a single frame.
+func LoadAnimation(fileName string) (*Animation, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return DecodeGIF(bytes.NewReader(data))
+	case bytes.HasPrefix(data, pngSignature):
+		return DecodeAPNG(bytes.NewReader(data))
+	}
+	return nil, fmt.Errorf("LoadAnimation(\"%v\"): %w", fileName, image.ErrFormat)
+}
+
+// defaultFrameDelay replaces frame delays of zero, as browsers do.
+const defaultFrameDelay = 100 * time.Millisecond
+
+// DecodeGIF decodes a GIF animation.
+func DecodeGIF(r io.Reader) (*Animation, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	anim := &Animation{}
+	switch {
+	case g.LoopCount < 0:
+		anim.Plays = 1
+	case g.LoopCount > 0:
+		anim.Plays = g.LoopCount + 1
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		anim.Frames = append(anim.Frames, cloneRGBA(canvas))
+
+		delay := time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		if delay == 0 {
+			delay = defaultFrameDelay
+		}
+		anim.Delays = append(anim.Delays, delay)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return anim, nil
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	clone := image.NewRGBA(img.Rect)
+	copy(clone.Pix, img.Pix)
+	return clone
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ErrAPNG indicates that an APNG file is malformed.
+const ErrAPNG constErr = "malformed APNG"
+
+type apngFrame struct {
+	rect    image.Rectangle
+	delay   time.Duration
+	dispose byte
+	blend   byte
+	data    []byte
+}
+
+// DecodeAPNG decodes an APNG animation. A PNG without animation decodes to a
+// single frame.
+func DecodeAPNG(r io.Reader) (*Animation, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("%w: no PNG signature", ErrAPNG)
+	}
+	var ihdr []byte
+	var shared bytes.Buffer // chunks other than frame data, copied to each frame
+	var frames []*apngFrame
+	var current *apngFrame
+	var animated, seenIDAT bool
+	plays := 0
+	for rest := data[len(pngSignature):]; len(rest) >= 12; {
+		length := binary.BigEndian.Uint32(rest)
+		if uint64(length)+12 > uint64(len(rest)) {
+			return nil, fmt.Errorf("%w: truncated chunk", ErrAPNG)
+		}
+		chunk := rest[:length+12]
+		typ := string(chunk[4:8])
+		body := chunk[8 : 8+length]
+		rest = rest[length+12:]
+
+		switch typ {
+		case "IHDR":
+			if length < 13 {
+				return nil, fmt.Errorf("%w: short IHDR", ErrAPNG)
+			}
+			ihdr = body
+		case "acTL":
+			if length < 8 {
+				return nil, fmt.Errorf("%w: short acTL", ErrAPNG)
+			}
+			animated = true
+			plays = int(binary.BigEndian.Uint32(body[4:]))
+		case "fcTL":
+			if length < 26 {
+				return nil, fmt.Errorf("%w: short fcTL", ErrAPNG)
+			}
+			w := int(binary.BigEndian.Uint32(body[4:]))
+			h := int(binary.BigEndian.Uint32(body[8:]))
+			x := int(binary.BigEndian.Uint32(body[12:]))
+			y := int(binary.BigEndian.Uint32(body[16:]))
+			num := binary.BigEndian.Uint16(body[20:])
+			den := binary.BigEndian.Uint16(body[22:])
+			if den == 0 {
+				den = 100
+			}
+			delay := time.Duration(num) * time.Second / time.Duration(den)
+			if delay == 0 {
+				delay = defaultFrameDelay
+			}
+			current = &apngFrame{
+				rect:    image.Rect(x, y, x+w, y+h),
+				delay:   delay,
+				dispose: body[24],
+				blend:   body[25],
+			}
+			frames = append(frames, current)
+		case "IDAT":
+			seenIDAT = true
+			// the default image is only part of the animation if an fcTL
+			// precedes it
+			if current != nil {
+				current.data = append(current.data, body...)
+			}
+		case "fdAT":
+			if length < 4 || current == nil {
+				return nil, fmt.Errorf("%w: unexpected fdAT", ErrAPNG)
+			}
+			current.data = append(current.data, body[4:]...)
+		case "IEND":
+		default:
+			if !seenIDAT {
+				shared.Write(chunk)
+			}
+		}
+	}
+	if ihdr == nil {
+		return nil, fmt.Errorf("%w: missing IHDR", ErrAPNG)
+	}
+	if !animated {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{Frames: []image.Image{img}, Delays: []time.Duration{defaultFrameDelay}}, nil
+	}
+
+	width := int(binary.BigEndian.Uint32(ihdr))
+	height := int(binary.BigEndian.Uint32(ihdr[4:]))
+	anim := &Animation{Plays: plays}
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, frame := range frames {
+		img, err := decodeAPNGFrame(ihdr, shared.Bytes(), frame)
+		if err != nil {
+			return nil, fmt.Errorf("frame %v: %w", i, err)
+		}
+		var previous *image.RGBA
+		dispose := frame.dispose
+		if dispose == 2 && i == 0 {
+			// there is nothing to revert to for the first frame
+			dispose = 1
+		}
+		if dispose == 2 {
+			previous = cloneRGBA(canvas)
+		}
+		op := draw.Src
+		if frame.blend == 1 {
+			op = draw.Over
+		}
+		draw.Draw(canvas, frame.rect, img, img.Bounds().Min, op)
+		anim.Frames = append(anim.Frames, cloneRGBA(canvas))
+		anim.Delays = append(anim.Delays, frame.delay)
+
+		switch dispose {
+		case 1:
+			draw.Draw(canvas, frame.rect, image.Transparent, image.Point{}, draw.Src)
+		case 2:
+			canvas = previous
+		}
+	}
+	return anim, nil
+}
+
+// decodeAPNGFrame decodes the data of an APNG frame by wrapping it in a
+// standalone PNG.
+func decodeAPNGFrame(ihdr, shared []byte, frame *apngFrame) (image.Image, error) {
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	header := append([]byte(nil), ihdr...)
+	binary.BigEndian.PutUint32(header, uint32(frame.rect.Dx()))
+	binary.BigEndian.PutUint32(header[4:], uint32(frame.rect.Dy()))
+	writePNGChunk(&buf, "IHDR", header)
+	buf.Write(shared)
+	writePNGChunk(&buf, "IDAT", frame.data)
+	writePNGChunk(&buf, "IEND", nil)
+	return png.Decode(&buf)
+}
+
+func writePNGChunk(buf *bytes.Buffer, typ string, body []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(body)))
+	buf.Write(length[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(body)
+	buf.WriteString(typ)
+	buf.Write(body)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	buf.Write(sum[:])
+}
+
+// Duration returns the time one play of the animation takes.
+func (a *Animation) Duration() time.Duration {
+	var total time.Duration
+	for _, d := range a.Delays {
+		total += d
+	}
+	return total
+}
+
+// NewTextureArray uploads the frames of the animation as the layers of a new
+// TextureArray.
+func (a *Animation) NewTextureArray() (TextureArray, error) {
+	return NewTextureArrayFromImages(a.Frames)
+}
+
+// AnimationFrameName returns the name of the given frame in an atlas created
+// by Animation.NewAtlas.
+func AnimationFrameName(frame int) string {
+	return strconv.Itoa(frame)
+}
+
+// NewAtlas packs the frames of the animation into a new Atlas, naming them
+// with AnimationFrameName.
+func (a *Animation) NewAtlas(padding, extrude int) (*Atlas, error) {
+	b := NewAtlasBuilder(padding, extrude)
+	for i, frame := range a.Frames {
+		if err := b.Add(AnimationFrameName(i), frame); err != nil {
+			return nil, err
+		}
+	}
+	return b.Build()
+}
+
+// AnimationPlayer selects the frame of an Animation to show at a given time.
+type AnimationPlayer struct {
+	anim    *Animation
+	regions []AtlasRegion
+}
+
+// NewAnimationPlayer creates an AnimationPlayer for anim.
+func NewAnimationPlayer(anim *Animation) *AnimationPlayer {
+	return &AnimationPlayer{anim: anim}
+}
+
+// NewAnimationPlayerAtlas creates an AnimationPlayer for anim whose frames
+// are stored in atlas, as created by Animation.NewAtlas.
+func NewAnimationPlayerAtlas(anim *Animation, atlas *Atlas) (*AnimationPlayer, error) {
+	p := &AnimationPlayer{anim: anim}
+	for i := range anim.Frames {
+		region, ok := atlas.Lookup(AnimationFrameName(i))
+		if !ok {
+			return nil, fmt.Errorf("%w: frame \"%v\" not in atlas", ErrInvalidName, AnimationFrameName(i))
+		}
+		p.regions = append(p.regions, region)
+	}
+	return p, nil
+}
+
+// Frame returns the index of the frame shown at time t since the start of the
+// animation. After the last play, the last frame is shown.
+func (p *AnimationPlayer) Frame(t time.Duration) int {
+	total := p.anim.Duration()
+	if len(p.anim.Frames) == 0 || total == 0 || t < 0 {
+		return 0
+	}
+	if p.anim.Plays > 0 && t >= total*time.Duration(p.anim.Plays) {
+		return len(p.anim.Frames) - 1
+	}
+	t %= total
+	for i, d := range p.anim.Delays {
+		if t < d {
+			return i
+		}
+		t -= d
+	}
+	return len(p.anim.Frames) - 1
+}
+
+// Layer returns the texture array layer shown at time t, for animations
+// uploaded with Animation.NewTextureArray.
+func (p *AnimationPlayer) Layer(t time.Duration) int32 {
+	return int32(p.Frame(t))
+}
+
+// Region returns the atlas region shown at time t, for players created with
+// NewAnimationPlayerAtlas.
+func (p *AnimationPlayer) Region(t time.Duration) AtlasRegion {
+	if len(p.regions) == 0 {
+		return AtlasRegion{}
+	}
+	return p.regions[p.Frame(t)]
+}
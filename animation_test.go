@@ -0,0 +1,162 @@
+package gfx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// pngChunks encodes img as a PNG and returns its IHDR body and the
+// concatenated IDAT bodies.
+func pngChunks(t *testing.T, img image.Image) (ihdr, idat []byte) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	for rest := buf.Bytes()[len(pngSignature):]; len(rest) >= 12; {
+		length := binary.BigEndian.Uint32(rest)
+		typ := string(rest[4:8])
+		body := rest[8 : 8+length]
+		switch typ {
+		case "IHDR":
+			ihdr = body
+		case "IDAT":
+			idat = append(idat, body...)
+		}
+		rest = rest[length+12:]
+	}
+	return ihdr, idat
+}
+
+// fcTL returns the body of an APNG frame control chunk.
+func fcTL(seq uint32, r image.Rectangle, num, den uint16, dispose, blend byte) []byte {
+	body := make([]byte, 26)
+	binary.BigEndian.PutUint32(body, seq)
+	binary.BigEndian.PutUint32(body[4:], uint32(r.Dx()))
+	binary.BigEndian.PutUint32(body[8:], uint32(r.Dy()))
+	binary.BigEndian.PutUint32(body[12:], uint32(r.Min.X))
+	binary.BigEndian.PutUint32(body[16:], uint32(r.Min.Y))
+	binary.BigEndian.PutUint16(body[20:], num)
+	binary.BigEndian.PutUint16(body[22:], den)
+	body[24] = dispose
+	body[25] = blend
+	return body
+}
+
+func uniformNRGBA(r image.Rectangle, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(r)
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+var (
+	testRed  = color.NRGBA{R: 255, A: 255}
+	testBlue = color.NRGBA{B: 255, A: 255}
+)
+
+// encodeTestAPNG returns a 2x2 APNG that plays twice: a red frame shown for
+// 100ms, followed by a blue 1x1 frame at (1, 1) with no delay.
+func encodeTestAPNG(t *testing.T, dispose byte) []byte {
+	ihdr, idat0 := pngChunks(t, uniformNRGBA(image.Rect(0, 0, 2, 2), testRed))
+	_, idat1 := pngChunks(t, uniformNRGBA(image.Rect(0, 0, 1, 1), testBlue))
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	writePNGChunk(&buf, "IHDR", ihdr)
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl, 2)
+	binary.BigEndian.PutUint32(actl[4:], 2)
+	writePNGChunk(&buf, "acTL", actl)
+	writePNGChunk(&buf, "fcTL", fcTL(0, image.Rect(0, 0, 2, 2), 1, 10, dispose, 0))
+	writePNGChunk(&buf, "IDAT", idat0)
+	writePNGChunk(&buf, "fcTL", fcTL(1, image.Rect(1, 1, 2, 2), 0, 0, 0, 0))
+	fdat := make([]byte, 4, 4+len(idat1))
+	binary.BigEndian.PutUint32(fdat, 2)
+	writePNGChunk(&buf, "fdAT", append(fdat, idat1...))
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	tests := []struct {
+		name    string
+		dispose byte
+		// want is the color of pixel (0, 0) and (1, 1) of the second frame.
+		want [2]color.NRGBA
+	}{
+		{"dispose none", 0, [2]color.NRGBA{testRed, testBlue}},
+		{"dispose background", 1, [2]color.NRGBA{{}, testBlue}},
+		// reverting the first frame is treated as dispose background
+		{"dispose previous", 2, [2]color.NRGBA{{}, testBlue}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim, err := DecodeAPNG(bytes.NewReader(encodeTestAPNG(t, tt.dispose)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(anim.Frames) != 2 {
+				t.Fatalf("got %v frames, want 2", len(anim.Frames))
+			}
+			if anim.Plays != 2 {
+				t.Errorf("Plays = %v, want 2", anim.Plays)
+			}
+			wantDelays := []time.Duration{100 * time.Millisecond, defaultFrameDelay}
+			for i, d := range anim.Delays {
+				if d != wantDelays[i] {
+					t.Errorf("delay %v = %v, want %v", i, d, wantDelays[i])
+				}
+			}
+			first := color.NRGBAModel.Convert(anim.Frames[0].At(1, 1))
+			if first != testRed {
+				t.Errorf("frame 0 at (1, 1) = %v, want %v", first, testRed)
+			}
+			for i, p := range []image.Point{{0, 0}, {1, 1}} {
+				got := color.NRGBAModel.Convert(anim.Frames[1].At(p.X, p.Y))
+				if got != tt.want[i] {
+					t.Errorf("frame 1 at %v = %v, want %v", p, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeAPNGStill(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, uniformNRGBA(image.Rect(0, 0, 3, 2), testBlue)); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := DecodeAPNG(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Frames) != 1 || anim.Frames[0].Bounds() != image.Rect(0, 0, 3, 2) {
+		t.Errorf("got %v frames, want a single 3x2 frame", len(anim.Frames))
+	}
+}
+
+func TestDecodeAPNGErrors(t *testing.T) {
+	valid := encodeTestAPNG(t, 0)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no signature", []byte("GIF89a")},
+		{"truncated", valid[:len(valid)-20]},
+		{"missing IHDR", pngSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeAPNG(bytes.NewReader(tt.data)); !errors.Is(err, ErrAPNG) {
+				t.Errorf("err = %v, want ErrAPNG", err)
+			}
+		})
+	}
+}
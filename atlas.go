@@ -0,0 +1,279 @@
+package gfx
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"math"
+	"sort"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// AtlasRegion locates a sprite within an Atlas.
+type AtlasRegion struct {
+	// Rect is the area of the sprite in texels, excluding padding and
+	// extrusion, with the top row of the sprite at Y.
+	Rect Rect
+	// U0, V0 are the normalized texture coordinates of the top left corner.
+	U0, V0 float32
+	// U1, V1 are the normalized texture coordinates of the bottom right corner.
+	U1, V1 float32
+}
+
+// Atlas is a texture holding many sprites.
+type Atlas struct {
+	texture Texture
+	img     *image.NRGBA
+	regions map[string]AtlasRegion
+}
+
+// GetTexture returns the atlas's OpenGL texture.
+func (a *Atlas) GetTexture() Texture {
+	return a.texture
+}
+
+// Image returns the packed atlas image.
+func (a *Atlas) Image() *image.NRGBA {
+	return a.img
+}
+
+// Lookup returns the region of the sprite with the given name.
+func (a *Atlas) Lookup(name string) (AtlasRegion, bool) {
+	region, ok := a.regions[name]
+	return region, ok
+}
+
+// Regions returns the regions of all sprites by name.
+func (a *Atlas) Regions() map[string]AtlasRegion {
+	return a.regions
+}
+
+// WritePNG saves the atlas image to fileName.
+func (a *Atlas) WritePNG(fileName string) error {
+	return writePNG(fileName, a.img)
+}
+
+type atlasManifest struct {
+	Width   int                       `json:"width"`
+	Height  int                       `json:"height"`
+	Sprites map[string]atlasSpriteDoc `json:"sprites"`
+}
+
+type atlasSpriteDoc struct {
+	X  int32   `json:"x"`
+	Y  int32   `json:"y"`
+	W  int32   `json:"w"`
+	H  int32   `json:"h"`
+	U0 float32 `json:"u0"`
+	V0 float32 `json:"v0"`
+	U1 float32 `json:"u1"`
+	V1 float32 `json:"v1"`
+}
+
+// WriteManifest saves the regions of all sprites to fileName as JSON.
+func (a *Atlas) WriteManifest(fileName string) error {
+	manifest := atlasManifest{
+		Width:   a.img.Bounds().Dx(),
+		Height:  a.img.Bounds().Dy(),
+		Sprites: make(map[string]atlasSpriteDoc, len(a.regions)),
+	}
+	for name, region := range a.regions {
+		manifest.Sprites[name] = atlasSpriteDoc{
+			X:  region.Rect.X,
+			Y:  region.Rect.Y,
+			W:  region.Rect.W,
+			H:  region.Rect.H,
+			U0: region.U0,
+			V0: region.V0,
+			U1: region.U1,
+			V1: region.V1,
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// Destroy frees external resources.
+func (a *Atlas) Destroy() {
+	a.texture.Destroy()
+}
+
+type atlasSprite struct {
+	name string
+	img  image.Image
+	x    int
+	y    int
+}
+
+// AtlasBuilder packs named images into an Atlas.
+type AtlasBuilder struct {
+	padding  int
+	extrude  int
+	maxWidth int
+	sprites  []atlasSprite
+	names    map[string]bool
+}
+
+// NewAtlasBuilder creates an AtlasBuilder that leaves padding transparent
+// texels between sprites and repeats the edge texels of each sprite extrude
+// times around it, to avoid bleeding when filtering.
+func NewAtlasBuilder(padding, extrude int) *AtlasBuilder {
+	return &AtlasBuilder{
+		padding:  padding,
+		extrude:  extrude,
+		maxWidth: 8192,
+		names:    make(map[string]bool),
+	}
+}
+
+// SetMaxWidth sets the maximum width of the atlas, 8192 by default.
+func (b *AtlasBuilder) SetMaxWidth(maxWidth int) {
+	b.maxWidth = maxWidth
+}
+
+// Add adds an image to be packed under the given name.
+//
+// Possible errors are ErrInvalidName if the name was already added and
+// ErrEmptyData if img is nil.
+func (b *AtlasBuilder) Add(name string, img image.Image) error {
+	if img == nil {
+		return fmt.Errorf("%w: nil image \"%v\"", ErrEmptyData, name)
+	}
+	if b.names[name] {
+		return fmt.Errorf("%w: duplicate \"%v\"", ErrInvalidName, name)
+	}
+	b.names[name] = true
+	b.sprites = append(b.sprites, atlasSprite{name: name, img: img})
+	return nil
+}
+
+// ErrAtlasFull indicates that the sprites do not fit in the maximum atlas size.
+const ErrAtlasFull constErr = "sprites do not fit in atlas"
+
+// Build packs the added images into a power of two sized RGBA texture.
+func (b *AtlasBuilder) Build() (*Atlas, error) {
+	if len(b.sprites) == 0 {
+		return nil, ErrEmptyData
+	}
+	// pack tallest sprites first into shelves
+	sort.SliceStable(b.sprites, func(i, j int) bool {
+		return b.sprites[i].img.Bounds().Dy() > b.sprites[j].img.Bounds().Dy()
+	})
+	var area, widest int
+	for _, s := range b.sprites {
+		w, h := b.cellSize(s.img)
+		area += w * h
+		if w > widest {
+			widest = w
+		}
+	}
+	width := nextPowerOfTwo(int(math.Sqrt(float64(area))))
+	if width < nextPowerOfTwo(widest+b.padding) {
+		width = nextPowerOfTwo(widest + b.padding)
+	}
+	var height int
+	for {
+		height = nextPowerOfTwo(b.pack(width))
+		if height <= width || width*2 > b.maxWidth {
+			break
+		}
+		width *= 2
+	}
+	if width > b.maxWidth || height > b.maxWidth {
+		return nil, fmt.Errorf("Build() %v sprites in %vx%v: %w", len(b.sprites), b.maxWidth, b.maxWidth, ErrAtlasFull)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	regions := make(map[string]AtlasRegion, len(b.sprites))
+	for _, s := range b.sprites {
+		b.blit(img, s)
+		sw, sh := s.img.Bounds().Dx(), s.img.Bounds().Dy()
+		x, y := s.x+b.extrude, s.y+b.extrude
+		regions[s.name] = AtlasRegion{
+			Rect: Rect{X: int32(x), Y: int32(y), W: int32(sw), H: int32(sh)},
+			U0:   float32(x) / float32(width),
+			V0:   float32(y) / float32(height),
+			U1:   float32(x+sw) / float32(width),
+			V1:   float32(y+sh) / float32(height),
+		}
+	}
+
+	tex, err := NewTexture(int32(width), int32(height), img.Pix, gl.RGBA, 4, 4)
+	if err != nil {
+		return nil, err
+	}
+	tex.SetTopRowFirst(true)
+	tex.SetParameter(gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_NEAREST)
+	tex.SetParameter(gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	return &Atlas{texture: tex, img: img, regions: regions}, nil
+}
+
+// cellSize returns the size of a sprite including extrusion and padding.
+func (b *AtlasBuilder) cellSize(img image.Image) (int, int) {
+	w := img.Bounds().Dx() + 2*b.extrude + b.padding
+	h := img.Bounds().Dy() + 2*b.extrude + b.padding
+	return w, h
+}
+
+// pack places every sprite in shelves of the given width and returns the
+// resulting height.
+func (b *AtlasBuilder) pack(width int) int {
+	x, y, shelfHeight := b.padding, b.padding, 0
+	for i := range b.sprites {
+		w, h := b.cellSize(b.sprites[i].img)
+		if x+w > width {
+			x = b.padding
+			y += shelfHeight
+			shelfHeight = 0
+		}
+		b.sprites[i].x, b.sprites[i].y = x, y
+		x += w
+		if h > shelfHeight {
+			shelfHeight = h
+		}
+	}
+	return y + shelfHeight
+}
+
+// blit copies a sprite into the atlas at its packed position, extruding its
+// edges.
+func (b *AtlasBuilder) blit(dst *image.NRGBA, s atlasSprite) {
+	bounds := s.img.Bounds()
+	src := imageToNRGBA(s.img)
+	sw, sh := bounds.Dx(), bounds.Dy()
+	if sw == 0 || sh == 0 {
+		return
+	}
+	for j := 0; j < sh+2*b.extrude; j++ {
+		sy := clampInt(j-b.extrude, 0, sh-1)
+		for i := 0; i < sw+2*b.extrude; i++ {
+			sx := clampInt(i-b.extrude, 0, sw-1)
+			si := (sy*sw + sx) * 4
+			di := dst.PixOffset(s.x+i, s.y+j)
+			copy(dst.Pix[di:di+4], src[si:si+4])
+		}
+	}
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func nextPowerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p *= 2
+	}
+	return p
+}
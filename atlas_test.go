@@ -0,0 +1,118 @@
+package gfx
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAtlasBuilderAdd(t *testing.T) {
+	b := NewAtlasBuilder(0, 0)
+	if err := b.Add("nil", nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("nil image: err = %v, want ErrEmptyData", err)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	if err := b.Add("nil", img); err != nil {
+		t.Errorf("name of rejected image: %v", err)
+	}
+	if err := b.Add("nil", img); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("duplicate name: err = %v, want ErrInvalidName", err)
+	}
+}
+
+func TestAtlasBuilderPack(t *testing.T) {
+	tests := []struct {
+		name             string
+		padding, extrude int
+		sizes            []image.Point
+		width            int
+		want             []image.Point
+		height           int
+	}{
+		{
+			"one shelf",
+			0, 0, []image.Point{{2, 2}, {2, 1}}, 4,
+			[]image.Point{{0, 0}, {2, 0}}, 2,
+		},
+		{
+			"padding",
+			1, 0, []image.Point{{2, 2}, {2, 2}}, 7,
+			[]image.Point{{1, 1}, {4, 1}}, 4,
+		},
+		{
+			"overflow to next shelf",
+			1, 0, []image.Point{{2, 2}, {2, 2}, {2, 1}}, 7,
+			[]image.Point{{1, 1}, {4, 1}, {1, 4}}, 6,
+		},
+		{
+			"extrude",
+			0, 1, []image.Point{{1, 1}, {1, 1}}, 4,
+			[]image.Point{{0, 0}, {0, 3}}, 6,
+		},
+		{
+			"wider than atlas",
+			0, 0, []image.Point{{2, 1}, {5, 1}}, 4,
+			[]image.Point{{0, 0}, {0, 1}}, 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewAtlasBuilder(tt.padding, tt.extrude)
+			for i, size := range tt.sizes {
+				if err := b.Add(AnimationFrameName(i), image.NewNRGBA(image.Rectangle{Max: size})); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := b.pack(tt.width); got != tt.height {
+				t.Errorf("height = %v, want %v", got, tt.height)
+			}
+			for i, s := range b.sprites {
+				if got := image.Pt(s.x, s.y); got != tt.want[i] {
+					t.Errorf("sprite %v at %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAtlasBuilderBlit(t *testing.T) {
+	sprite := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	sprite.SetNRGBA(0, 0, testRed)
+	sprite.SetNRGBA(1, 0, testBlue)
+	tests := []struct {
+		name    string
+		extrude int
+		// want holds the expected colors of the rows of a 6x4 atlas with the
+		// sprite at (1, 1), where r is red, b is blue and . is transparent.
+		want []string
+	}{
+		{"no extrusion", 0, []string{
+			"......",
+			".rb...",
+			"......",
+			"......",
+		}},
+		{"extrude", 1, []string{
+			"......",
+			".rrbb.",
+			".rrbb.",
+			".rrbb.",
+		}},
+	}
+	colors := map[byte]color.NRGBA{'r': testRed, 'b': testBlue, '.': {}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewAtlasBuilder(0, tt.extrude)
+			dst := image.NewNRGBA(image.Rect(0, 0, 6, 4))
+			b.blit(dst, atlasSprite{img: sprite, x: 1, y: 1})
+			for y, row := range tt.want {
+				for x := range row {
+					if got := dst.NRGBAAt(x, y); got != colors[row[x]] {
+						t.Errorf("(%v, %v) = %v, want %v", x, y, got, colors[row[x]])
+					}
+				}
+			}
+		})
+	}
+}
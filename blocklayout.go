@@ -0,0 +1,389 @@
+package gfx
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// BlockLayout is the memory layout of an interface block in a shader.
+type BlockLayout int
+
+const (
+	// Std140 is the layout of uniform blocks declared with layout(std140).
+	Std140 BlockLayout = iota
+	// Std430 is the layout of shader storage blocks declared with
+	// layout(std430). It packs arrays and structs tighter than Std140.
+	Std430
+)
+
+// ErrBlockType indicates that a Go type can not be represented in a shader
+// interface block.
+const ErrBlockType constErr = "type not supported in shader block"
+
+type blockKind int
+
+const (
+	blockScalar blockKind = iota
+	blockVector
+	blockArray
+	blockMatrix
+	blockStruct
+)
+
+// blockType describes how a Go type is laid out in an interface block.
+type blockType struct {
+	kind blockKind
+	// n is the number of components of a vector, elements of an array or
+	// columns of a matrix.
+	n int
+	// rows is the number of rows of a matrix.
+	rows   int
+	elem   *blockType
+	fields []blockField
+	size   int
+	align  int
+	// stride is the distance between elements of an array or columns of a
+	// matrix.
+	stride int
+}
+
+type blockField struct {
+	index  int
+	offset int
+	typ    *blockType
+}
+
+type blockTypeKey struct {
+	t      reflect.Type
+	layout BlockLayout
+}
+
+var blockTypes sync.Map
+
+// EncodeStd140 encodes v as the data of a uniform block with std140 layout.
+// See EncodeBlock.
+func EncodeStd140(v interface{}) ([]byte, error) {
+	return EncodeBlock(Std140, v)
+}
+
+// EncodeStd430 encodes v as the data of a shader storage block with std430
+// layout. See EncodeBlock.
+func EncodeStd430(v interface{}) ([]byte, error) {
+	return EncodeBlock(Std430, v)
+}
+
+// EncodeBlock encodes v, usually a struct or a pointer to one, as the data of
+// an interface block with the given layout, inserting the padding the layout
+// requires.
+//
+// Go types map to GLSL types as follows:
+//
+//	float32, int32, uint32, bool    float, int, uint, bool
+//	[N]float32 with N from 2 to 4   vecN (ivecN, uvecN, bvecN likewise)
+//	[C][R]float32                   matCxR, e.g. [4][4]float32 is mat4
+//	[N]T                            T[N]
+//	struct                          struct, skipping unexported fields
+//
+// Matrices are column-major, as in GLSL. The glsl field tag changes how a
+// field is encoded: "matC" or "matCxR" encodes a flat [C*R]float32 as a
+// matrix, "array" encodes a [2]–[4] array of scalars as an array instead of
+// a vector and "-" skips the field.
+//
+// Possible errors are ErrBlockType.
+func EncodeBlock(layout BlockLayout, v interface{}) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("EncodeBlock(nil): %w", ErrBlockType)
+	}
+	t, err := blockTypeOf(rv.Type(), layout)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, t.size)
+	encodeBlockValue(buf, 0, rv, t)
+	return buf, nil
+}
+
+// DecodeBlock decodes data of an interface block with the given layout into v,
+// which must be a pointer, as the reverse of EncodeBlock.
+//
+// Possible errors are ErrBlockType and ErrDataSize if data is too short.
+func DecodeBlock(layout BlockLayout, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("DecodeBlock(%T): %w", v, ErrBlockType)
+	}
+	t, err := blockTypeOf(rv.Elem().Type(), layout)
+	if err != nil {
+		return err
+	}
+	if len(data) < t.size {
+		return fmt.Errorf("DecodeBlock: %v bytes, want %v: %w", len(data), t.size, ErrDataSize)
+	}
+	decodeBlockValue(data, 0, rv.Elem(), t)
+	return nil
+}
+
+// BlockStride returns the distance in bytes between the elements of an array
+// of T with the given layout, such as a runtime-sized array in a shader
+// storage block.
+//
+// Possible errors are ErrBlockType.
+func BlockStride[T any](layout BlockLayout) (int, error) {
+	var zero T
+	t, err := blockTypeOf(reflect.TypeOf(zero), layout)
+	if err != nil {
+		return 0, err
+	}
+	return blockArrayStride(t, layout), nil
+}
+
+// EncodeSlice encodes s as an array of T with the given layout.
+//
+// Possible errors are ErrBlockType.
+func EncodeSlice[T any](layout BlockLayout, s []T) ([]byte, error) {
+	var zero T
+	t, err := blockTypeOf(reflect.TypeOf(zero), layout)
+	if err != nil {
+		return nil, err
+	}
+	stride := blockArrayStride(t, layout)
+	buf := make([]byte, stride*len(s))
+	for i := range s {
+		encodeBlockValue(buf, i*stride, reflect.ValueOf(&s[i]).Elem(), t)
+	}
+	return buf, nil
+}
+
+// DecodeSlice decodes data holding an array of T with the given layout. Any
+// trailing bytes that do not hold a whole element are ignored.
+//
+// Possible errors are ErrBlockType.
+func DecodeSlice[T any](layout BlockLayout, data []byte) ([]T, error) {
+	var zero T
+	t, err := blockTypeOf(reflect.TypeOf(zero), layout)
+	if err != nil {
+		return nil, err
+	}
+	stride := blockArrayStride(t, layout)
+	s := make([]T, len(data)/stride)
+	for i := range s {
+		decodeBlockValue(data, i*stride, reflect.ValueOf(&s[i]).Elem(), t)
+	}
+	return s, nil
+}
+
+// blockArrayStride returns the array stride of elements with layout t.
+func blockArrayStride(t *blockType, layout BlockLayout) int {
+	stride := roundUp(t.size, t.align)
+	if layout == Std140 {
+		stride = roundUp(stride, 16)
+	}
+	return stride
+}
+
+// blockTypeOf returns the cached layout of t.
+func blockTypeOf(t reflect.Type, layout BlockLayout) (*blockType, error) {
+	key := blockTypeKey{t: t, layout: layout}
+	if bt, ok := blockTypes.Load(key); ok {
+		return bt.(*blockType), nil
+	}
+	bt, err := newBlockType(t, "", layout)
+	if err != nil {
+		return nil, err
+	}
+	blockTypes.Store(key, bt)
+	return bt, nil
+}
+
+func isBlockScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Float32, reflect.Int32, reflect.Uint32, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+func roundUp(n, multiple int) int {
+	return (n + multiple - 1) / multiple * multiple
+}
+
+// newBlockType computes the layout of t, which has the given glsl tag.
+func newBlockType(t reflect.Type, tag string, layout BlockLayout) (*blockType, error) {
+	switch {
+	case isBlockScalar(t.Kind()):
+		return &blockType{kind: blockScalar, size: 4, align: 4}, nil
+	case t.Kind() == reflect.Array && strings.HasPrefix(tag, "mat"):
+		return newBlockMatrix(t, tag, layout)
+	case t.Kind() == reflect.Array && isBlockScalar(t.Elem().Kind()) &&
+		t.Len() >= 2 && t.Len() <= 4 && tag != "array":
+		align := 16
+		if t.Len() == 2 {
+			align = 8
+		}
+		return &blockType{kind: blockVector, n: t.Len(), size: 4 * t.Len(), align: align}, nil
+	case t.Kind() == reflect.Array:
+		elem, err := newBlockType(t.Elem(), "", layout)
+		if err != nil {
+			return nil, err
+		}
+		return newBlockArray(blockArray, t.Len(), elem, layout), nil
+	case t.Kind() == reflect.Struct:
+		return newBlockStruct(t, layout)
+	}
+	return nil, fmt.Errorf("%v: %w", t, ErrBlockType)
+}
+
+// newBlockArray returns the layout of an array of n elements, or of a matrix
+// with n columns.
+func newBlockArray(kind blockKind, n int, elem *blockType, layout BlockLayout) *blockType {
+	stride := blockArrayStride(elem, layout)
+	align := elem.align
+	if layout == Std140 {
+		align = roundUp(align, 16)
+	}
+	return &blockType{kind: kind, n: n, elem: elem, size: stride * n, align: align, stride: stride}
+}
+
+// newBlockMatrix returns the layout of a flat array of floats tagged "matC" or
+// "matCxR".
+func newBlockMatrix(t reflect.Type, tag string, layout BlockLayout) (*blockType, error) {
+	dims := strings.SplitN(strings.TrimPrefix(tag, "mat"), "x", 2)
+	cols, err := strconv.Atoi(dims[0])
+	rows := cols
+	if err == nil && len(dims) == 2 {
+		rows, err = strconv.Atoi(dims[1])
+	}
+	if err != nil || cols < 2 || cols > 4 || rows < 2 || rows > 4 ||
+		t.Elem().Kind() != reflect.Float32 || t.Len() != cols*rows {
+		return nil, fmt.Errorf("%v with tag \"%v\": %w", t, tag, ErrBlockType)
+	}
+	column, err := newBlockType(reflect.ArrayOf(rows, t.Elem()), "", layout)
+	if err != nil {
+		return nil, err
+	}
+	m := newBlockArray(blockMatrix, cols, column, layout)
+	m.rows = rows
+	return m, nil
+}
+
+// newBlockStruct returns the layout of a struct.
+func newBlockStruct(t reflect.Type, layout BlockLayout) (*blockType, error) {
+	bt := &blockType{kind: blockStruct, align: 4}
+	offset := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("glsl")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		ft, err := newBlockType(f.Type, tag, layout)
+		if err != nil {
+			return nil, fmt.Errorf("%v.%v: %w", t, f.Name, err)
+		}
+		offset = roundUp(offset, ft.align)
+		bt.fields = append(bt.fields, blockField{index: i, offset: offset, typ: ft})
+		offset += ft.size
+		if ft.align > bt.align {
+			bt.align = ft.align
+		}
+	}
+	if len(bt.fields) == 0 {
+		return nil, fmt.Errorf("%v has no fields: %w", t, ErrBlockType)
+	}
+	if layout == Std140 {
+		bt.align = roundUp(bt.align, 16)
+	}
+	bt.size = roundUp(offset, bt.align)
+	return bt, nil
+}
+
+// encodeBlockValue writes v with layout t to buf at offset, in the native
+// byte order as read by OpenGL.
+func encodeBlockValue(buf []byte, offset int, v reflect.Value, t *blockType) {
+	switch t.kind {
+	case blockScalar:
+		putBlockScalar(buf[offset:], v)
+	case blockVector:
+		for i := 0; i < t.n; i++ {
+			putBlockScalar(buf[offset+4*i:], v.Index(i))
+		}
+	case blockArray:
+		for i := 0; i < t.n; i++ {
+			encodeBlockValue(buf, offset+i*t.stride, v.Index(i), t.elem)
+		}
+	case blockMatrix:
+		for c := 0; c < t.n; c++ {
+			for r := 0; r < t.rows; r++ {
+				putBlockScalar(buf[offset+c*t.stride+4*r:], v.Index(c*t.rows+r))
+			}
+		}
+	case blockStruct:
+		for _, f := range t.fields {
+			encodeBlockValue(buf, offset+f.offset, v.Field(f.index), f.typ)
+		}
+	}
+}
+
+func putBlockScalar(buf []byte, v reflect.Value) {
+	var bits uint32
+	switch v.Kind() {
+	case reflect.Float32:
+		bits = math.Float32bits(float32(v.Float()))
+	case reflect.Int32:
+		bits = uint32(v.Int())
+	case reflect.Uint32:
+		bits = uint32(v.Uint())
+	case reflect.Bool:
+		if v.Bool() {
+			bits = 1
+		}
+	}
+	*(*uint32)(unsafe.Pointer(&buf[0])) = bits
+}
+
+// decodeBlockValue reads v with layout t from buf at offset, as the reverse of
+// encodeBlockValue.
+func decodeBlockValue(buf []byte, offset int, v reflect.Value, t *blockType) {
+	switch t.kind {
+	case blockScalar:
+		getBlockScalar(buf[offset:], v)
+	case blockVector:
+		for i := 0; i < t.n; i++ {
+			getBlockScalar(buf[offset+4*i:], v.Index(i))
+		}
+	case blockArray:
+		for i := 0; i < t.n; i++ {
+			decodeBlockValue(buf, offset+i*t.stride, v.Index(i), t.elem)
+		}
+	case blockMatrix:
+		for c := 0; c < t.n; c++ {
+			for r := 0; r < t.rows; r++ {
+				getBlockScalar(buf[offset+c*t.stride+4*r:], v.Index(c*t.rows+r))
+			}
+		}
+	case blockStruct:
+		for _, f := range t.fields {
+			decodeBlockValue(buf, offset+f.offset, v.Field(f.index), f.typ)
+		}
+	}
+}
+
+func getBlockScalar(buf []byte, v reflect.Value) {
+	bits := *(*uint32)(unsafe.Pointer(&buf[0]))
+	switch v.Kind() {
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(bits)))
+	case reflect.Int32:
+		v.SetInt(int64(int32(bits)))
+	case reflect.Uint32:
+		v.SetUint(uint64(bits))
+	case reflect.Bool:
+		v.SetBool(bits != 0)
+	}
+}
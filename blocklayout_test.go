@@ -0,0 +1,235 @@
+package gfx
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+type blockVec3Float struct {
+	V [3]float32
+	F float32
+}
+
+type blockMat2 struct {
+	M [2][2]float32
+	F float32
+}
+
+type blockMat3 struct {
+	M [3][3]float32
+	F float32
+}
+
+type blockMat3x2 struct {
+	M [6]float32 `glsl:"mat3x2"`
+	F float32
+}
+
+type blockFloatArray struct {
+	A float32
+	F [3]float32 `glsl:"array"`
+	B float32
+}
+
+type blockPair struct {
+	X, Y float32
+}
+
+type blockStructArray struct {
+	A float32
+	L [2]blockPair
+	B float32
+}
+
+type blockSkipped struct {
+	A bool
+	b float32
+	C float32 `glsl:"-"`
+	D int32
+}
+
+// blockSample is
+//
+//	float a; vec2 b; vec3 c; float d; mat4 m; mat3 m3; float f[3];
+//	struct { vec3 v; float w; } l[2];
+type blockSample struct {
+	A  float32
+	B  [2]float32
+	C  [3]float32
+	D  float32
+	M  [16]float32 `glsl:"mat4"`
+	M3 [3][3]float32
+	F  [3]float32 `glsl:"array"`
+	L  [2]blockVec3Float
+}
+
+func TestBlockLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		layout  BlockLayout
+		offsets []int
+		size    int
+	}{
+		{"vec3 float std140", blockVec3Float{}, Std140, []int{0, 12}, 16},
+		{"vec3 float std430", blockVec3Float{}, Std430, []int{0, 12}, 16},
+		{"mat2 std140", blockMat2{}, Std140, []int{0, 32}, 48},
+		{"mat2 std430", blockMat2{}, Std430, []int{0, 16}, 24},
+		{"mat3 std140", blockMat3{}, Std140, []int{0, 48}, 64},
+		{"mat3 std430", blockMat3{}, Std430, []int{0, 48}, 64},
+		{"mat3x2 tag std140", blockMat3x2{}, Std140, []int{0, 48}, 64},
+		{"mat3x2 tag std430", blockMat3x2{}, Std430, []int{0, 24}, 32},
+		{"array tag std140", blockFloatArray{}, Std140, []int{0, 16, 64}, 80},
+		{"array tag std430", blockFloatArray{}, Std430, []int{0, 4, 16}, 20},
+		{"struct array std140", blockStructArray{}, Std140, []int{0, 16, 48}, 64},
+		{"struct array std430", blockStructArray{}, Std430, []int{0, 4, 20}, 24},
+		{"skipped fields std140", blockSkipped{}, Std140, []int{0, 4}, 16},
+		{"skipped fields std430", blockSkipped{}, Std430, []int{0, 4}, 8},
+		{"sample std140", blockSample{}, Std140, []int{0, 8, 16, 28, 32, 96, 144, 192}, 224},
+		{"sample std430", blockSample{}, Std430, []int{0, 8, 16, 28, 32, 96, 144, 160}, 192},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bt, err := blockTypeOf(reflect.TypeOf(tt.v), tt.layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var offsets []int
+			for _, f := range bt.fields {
+				offsets = append(offsets, f.offset)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("offsets = %v, want %v", offsets, tt.offsets)
+			}
+			if bt.size != tt.size {
+				t.Errorf("size = %v, want %v", bt.size, tt.size)
+			}
+			data, err := EncodeBlock(tt.layout, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != tt.size {
+				t.Errorf("len(EncodeBlock) = %v, want %v", len(data), tt.size)
+			}
+		})
+	}
+}
+
+func TestBlockMatrixStride(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      interface{}
+		layout BlockLayout
+		stride int
+	}{
+		{"mat2 std140", blockMat2{}, Std140, 16},
+		{"mat2 std430", blockMat2{}, Std430, 8},
+		{"mat3 std140", blockMat3{}, Std140, 16},
+		{"mat3 std430", blockMat3{}, Std430, 16},
+		{"mat3x2 std140", blockMat3x2{}, Std140, 16},
+		{"mat3x2 std430", blockMat3x2{}, Std430, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bt, err := blockTypeOf(reflect.TypeOf(tt.v), tt.layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bt.fields[0].typ.stride; got != tt.stride {
+				t.Errorf("column stride = %v, want %v", got, tt.stride)
+			}
+		})
+	}
+}
+
+func TestBlockStride(t *testing.T) {
+	tests := []struct {
+		name   string
+		stride func(BlockLayout) (int, error)
+		layout BlockLayout
+		want   int
+	}{
+		{"float std140", BlockStride[float32], Std140, 16},
+		{"float std430", BlockStride[float32], Std430, 4},
+		{"vec2 std140", BlockStride[[2]float32], Std140, 16},
+		{"vec2 std430", BlockStride[[2]float32], Std430, 8},
+		{"vec3 std430", BlockStride[[3]float32], Std430, 16},
+		{"struct std140", BlockStride[blockPair], Std140, 16},
+		{"struct std430", BlockStride[blockPair], Std430, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.stride(tt.layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("stride = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeBlockValues(t *testing.T) {
+	v := blockVec3Float{V: [3]float32{1, 2, 3}, F: 4}
+	data, err := EncodeStd140(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float32{1, 2, 3, 4} {
+		got := math.Float32frombits(*(*uint32)(unsafe.Pointer(&data[4*i])))
+		if got != want {
+			t.Errorf("float %v = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestDecodeBlock(t *testing.T) {
+	v := blockSample{A: 1, B: [2]float32{2, 3}, C: [3]float32{4, 5, 6}, D: 7}
+	for i := range v.M {
+		v.M[i] = float32(10 + i)
+	}
+	v.M3 = [3][3]float32{{30, 31, 32}, {33, 34, 35}, {36, 37, 38}}
+	v.F = [3]float32{40, 41, 42}
+	v.L = [2]blockVec3Float{{V: [3]float32{50, 51, 52}, F: 53}, {V: [3]float32{54, 55, 56}, F: 57}}
+	for _, layout := range []BlockLayout{Std140, Std430} {
+		data, err := EncodeBlock(layout, &v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got blockSample
+		if err := DecodeBlock(layout, data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != v {
+			t.Errorf("layout %v: DecodeBlock = %v, want %v", layout, got, v)
+		}
+	}
+}
+
+func TestBlockTypeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"float64", struct{ F float64 }{}},
+		{"bad matrix tag", struct {
+			M [4]float32 `glsl:"mat5"`
+		}{}},
+		{"matrix size mismatch", struct {
+			M [4]float32 `glsl:"mat3"`
+		}{}},
+		{"no fields", struct{ f float32 }{}},
+		{"nil", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeStd140(tt.v); !errors.Is(err, ErrBlockType) {
+				t.Errorf("err = %v, want ErrBlockType", err)
+			}
+		})
+	}
+}
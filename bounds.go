@@ -0,0 +1,95 @@
+package gfx
+
+import (
+	"fmt"
+)
+
+// AreaError describes an area that does not fit within a texture.
+// It wraps ErrCoordOutOfRange.
+type AreaError struct {
+	Op string
+	// X, Y, Z, W, H and D are the offset and size of the requested area.
+	X, Y, Z, W, H, D int32
+	// Width, Height and Depth are the size of the texture (level).
+	Width, Height, Depth int32
+}
+
+func (e *AreaError) Error() string {
+	return fmt.Sprintf("%v: area %vx%vx%v at (%v, %v, %v) does not fit in %vx%vx%v: %v",
+		e.Op, e.W, e.H, e.D, e.X, e.Y, e.Z, e.Width, e.Height, e.Depth, ErrCoordOutOfRange)
+}
+
+// Unwrap returns ErrCoordOutOfRange.
+func (e *AreaError) Unwrap() error {
+	return ErrCoordOutOfRange
+}
+
+// ErrDataSize indicates that the size of data does not match its dimensions.
+const ErrDataSize constErr = "data size does not match dimensions"
+
+// DataSizeError describes data whose length does not match the area it is
+// uploaded to. It wraps ErrDataSize.
+type DataSizeError struct {
+	Op string
+	// W, H and D are the size of the area.
+	W, H, D int32
+	// Got is the length of the data, and Min and Max the accepted lengths,
+	// which differ when rows are padded to the alignment but the last row is
+	// not.
+	Got, Min, Max int
+}
+
+func (e *DataSizeError) Error() string {
+	if e.Min == e.Max {
+		return fmt.Sprintf("%v: %v bytes for %vx%vx%v, want %v: %v",
+			e.Op, e.Got, e.W, e.H, e.D, e.Max, ErrDataSize)
+	}
+	return fmt.Sprintf("%v: %v bytes for %vx%vx%v, want %v to %v: %v",
+		e.Op, e.Got, e.W, e.H, e.D, e.Min, e.Max, ErrDataSize)
+}
+
+// Unwrap returns ErrDataSize.
+func (e *DataSizeError) Unwrap() error {
+	return ErrDataSize
+}
+
+// alignedRowSize returns the size in bytes of a row of width texels with
+// each row starting on a multiple of alignment bytes, as for glPixelStorei.
+func alignedRowSize(width, texelSize, alignment int32) int {
+	row := int(width) * int(texelSize)
+	if alignment <= 1 {
+		return row
+	}
+	a := int(alignment)
+	return (row + a - 1) / a * a
+}
+
+// alignedDataSize returns the size in bytes of a w*h*d area including row
+// padding.
+func alignedDataSize(w, h, d, texelSize, alignment int32) int {
+	return alignedRowSize(w, texelSize, alignment) * int(h) * int(d)
+}
+
+// checkArea returns an *AreaError if the area is empty or does not fit in a
+// width*height*depth texture.
+func checkArea(op string, x, y, z, w, h, d, width, height, depth int32) error {
+	if x < 0 || y < 0 || z < 0 || w <= 0 || h <= 0 || d <= 0 ||
+		x+w > width || y+h > height || z+d > depth {
+		return &AreaError{
+			Op: op, X: x, Y: y, Z: z, W: w, H: h, D: d,
+			Width: width, Height: height, Depth: depth,
+		}
+	}
+	return nil
+}
+
+// checkDataSize returns a *DataSizeError if got bytes can not hold a w*h*d
+// area with the given alignment. The padding of the last row may be omitted.
+func checkDataSize(op string, got int, w, h, d, texelSize, alignment int32) error {
+	maxSize := alignedDataSize(w, h, d, texelSize, alignment)
+	minSize := maxSize - alignedRowSize(w, texelSize, alignment) + int(w)*int(texelSize)
+	if got < minSize || got > maxSize {
+		return &DataSizeError{Op: op, W: w, H: h, D: d, Got: got, Min: minSize, Max: maxSize}
+	}
+	return nil
+}
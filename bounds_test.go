@@ -0,0 +1,95 @@
+package gfx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAlignedRowSize(t *testing.T) {
+	tests := []struct {
+		width, texelSize, alignment int32
+		want                        int
+	}{
+		{3, 1, 1, 3},
+		{3, 1, 4, 4},
+		{3, 3, 4, 12},
+		{5, 3, 4, 16},
+		{5, 3, 8, 16},
+		{4, 4, 8, 16},
+		{3, 1, 0, 3},
+	}
+	for _, tt := range tests {
+		got := alignedRowSize(tt.width, tt.texelSize, tt.alignment)
+		if got != tt.want {
+			t.Errorf("alignedRowSize(%v, %v, %v) = %v, want %v",
+				tt.width, tt.texelSize, tt.alignment, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDataSize(t *testing.T) {
+	tests := []struct {
+		name                          string
+		got                           int
+		w, h, d, texelSize, alignment int32
+		ok                            bool
+	}{
+		{"padded rows", 8, 3, 2, 1, 1, 4, true},
+		{"last row unpadded", 7, 3, 2, 1, 1, 4, true},
+		{"too short", 6, 3, 2, 1, 1, 4, false},
+		{"too long", 9, 3, 2, 1, 1, 4, false},
+		{"unaligned", 6, 3, 2, 1, 1, 1, true},
+		{"unaligned too long", 7, 3, 2, 1, 1, 1, false},
+		{"rgba", 32, 4, 2, 1, 4, 4, true},
+		{"rgba short", 31, 4, 2, 1, 4, 4, false},
+		{"layers", 3 * 8, 3, 2, 3, 1, 4, true},
+		{"layers last row unpadded", 3*8 - 1, 3, 2, 3, 1, 4, true},
+		{"layers missing a row", 3*8 - 4, 3, 2, 3, 1, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDataSize("test", tt.got, tt.w, tt.h, tt.d, tt.texelSize, tt.alignment)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok {
+				var sizeErr *DataSizeError
+				if !errors.As(err, &sizeErr) || !errors.Is(err, ErrDataSize) {
+					t.Errorf("err = %v, want *DataSizeError", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckArea(t *testing.T) {
+	tests := []struct {
+		name                 string
+		x, y, z, w, h, d     int32
+		width, height, depth int32
+		ok                   bool
+	}{
+		{"whole", 0, 0, 0, 4, 4, 1, 4, 4, 1, true},
+		{"inside", 1, 2, 0, 2, 2, 1, 4, 4, 1, true},
+		{"last layer", 0, 0, 2, 4, 4, 1, 4, 4, 3, true},
+		{"negative", -1, 0, 0, 2, 2, 1, 4, 4, 1, false},
+		{"empty", 0, 0, 0, 0, 2, 1, 4, 4, 1, false},
+		{"too wide", 3, 0, 0, 2, 2, 1, 4, 4, 1, false},
+		{"too tall", 0, 3, 0, 2, 2, 1, 4, 4, 1, false},
+		{"too deep", 0, 0, 3, 4, 4, 1, 4, 4, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkArea("test", tt.x, tt.y, tt.z, tt.w, tt.h, tt.d, tt.width, tt.height, tt.depth)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok {
+				var areaErr *AreaError
+				if !errors.As(err, &areaErr) || !errors.Is(err, ErrCoordOutOfRange) {
+					t.Errorf("err = %v, want *AreaError", err)
+				}
+			}
+		})
+	}
+}
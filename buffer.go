@@ -0,0 +1,184 @@
+package gfx
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Buffer is a typed OpenGL buffer holding elements of type T. T must be plain
+// data without Go pointers, such as float32, [3]float32 or a struct of those,
+// laid out in memory as the shaders expect it.
+type Buffer[T any] struct {
+	bo     *BufferObject
+	target uint32
+	usage  uint32
+}
+
+// NewBuffer returns a new, empty buffer that is bound to target (e.g.
+// gl.ARRAY_BUFFER) when accessed and allocated with the given usage (e.g.
+// gl.STATIC_DRAW).
+func NewBuffer[T any](target, usage uint32) *Buffer[T] {
+	return &Buffer[T]{
+		bo:     NewBufferObject(),
+		target: target,
+		usage:  usage,
+	}
+}
+
+// elementSize returns the size of T in bytes.
+func elementSize[T any]() int {
+	var zero T
+	return int(unsafe.Sizeof(zero))
+}
+
+// slicePtr returns a pointer to the first element of s, or nil if s is empty.
+func slicePtr[T any](s []T) unsafe.Pointer {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&s[0])
+}
+
+// Upload replaces the data store of the buffer with data, reallocating it to
+// the length of data. Use Update for buffers with immutable storage.
+//
+// Possible errors are ErrBufferImmutable.
+func (b *Buffer[T]) Upload(data []T) error {
+	return b.bo.BufferData(b.target, uint32(len(data)*elementSize[T]()), slicePtr(data), b.usage)
+}
+
+// Update overwrites the elements starting at element offset with data. It
+// can not grow the buffer.
+//
+// Possible errors are ErrOutOfBounds.
+func (b *Buffer[T]) Update(offset int, data []T) error {
+	if offset < 0 || offset+len(data) > b.Len() {
+		return fmt.Errorf("Update(%v, %v): %w: buffer length %v", offset, len(data), ErrOutOfBounds, b.Len())
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	size := elementSize[T]()
+	return b.bo.BufferSubData(b.target, uint32(offset*size), uint32(len(data)*size), slicePtr(data))
+}
+
+// Resize reallocates the buffer to hold length elements, keeping the elements
+// that fit (see BufferObject.Resize).
+func (b *Buffer[T]) Resize(length int) error {
+	return b.bo.Resize(b.target, uint32(length*elementSize[T]()))
+}
+
+// Reserve grows the buffer to hold at least length elements, keeping its
+// contents (see BufferObject.Reserve).
+func (b *Buffer[T]) Reserve(length int) error {
+	return b.bo.Reserve(b.target, uint32(length*elementSize[T]()))
+}
+
+// Read returns a copy of all elements of the buffer.
+func (b *Buffer[T]) Read() []T {
+	data := make([]T, b.Len())
+	if len(data) > 0 {
+		b.bo.GetData(b.target, slicePtr(data))
+	}
+	return data
+}
+
+// ReadRange returns a copy of count elements starting at element offset.
+//
+// Possible errors are ErrOutOfBounds.
+func (b *Buffer[T]) ReadRange(offset, count int) ([]T, error) {
+	if offset < 0 || count < 0 || offset+count > b.Len() {
+		return nil, fmt.Errorf("ReadRange(%v, %v): %w: buffer length %v", offset, count, ErrOutOfBounds, b.Len())
+	}
+	data := make([]T, count)
+	if count > 0 {
+		size := elementSize[T]()
+		b.bo.GetBufferSubData(b.target, uint32(offset*size), uint32(count*size), slicePtr(data))
+	}
+	return data, nil
+}
+
+// Storage creates an immutable data store holding data (see
+// BufferObject.BufferStorage).
+func (b *Buffer[T]) Storage(data []T, flags uint32) {
+	b.bo.BufferStorage(b.target, uint32(len(data)*elementSize[T]()), slicePtr(data), flags)
+}
+
+// StorageLen creates an immutable, uninitialized data store for length
+// elements (see BufferObject.BufferStorage).
+func (b *Buffer[T]) StorageLen(length int, flags uint32) {
+	b.bo.BufferStorage(b.target, uint32(length*elementSize[T]()), nil, flags)
+}
+
+// Map maps all elements of the buffer into client memory (see
+// BufferObject.MapRange).
+func (b *Buffer[T]) Map(access uint32) ([]T, error) {
+	return b.MapRange(0, b.Len(), access)
+}
+
+// MapRange maps count elements starting at element offset into client memory
+// (see BufferObject.MapRange).
+//
+// Possible errors are ErrOutOfBounds and ErrMapBuffer.
+func (b *Buffer[T]) MapRange(offset, count int, access uint32) ([]T, error) {
+	if offset < 0 || count < 0 {
+		return nil, fmt.Errorf("MapRange(%v, %v): %w", offset, count, ErrOutOfBounds)
+	}
+	size := elementSize[T]()
+	data, err := b.bo.MapRange(b.target, uint32(offset*size), uint32(count*size), access)
+	if err != nil {
+		return nil, err
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(&data[0])), count), nil
+}
+
+// MapPersistent maps all elements of a buffer with persistent storage (see
+// BufferObject.MapPersistent).
+func (b *Buffer[T]) MapPersistent() ([]T, error) {
+	data, err := b.bo.MapPersistent(b.target)
+	if err != nil {
+		return nil, err
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(&data[0])), b.Len()), nil
+}
+
+// Unmap unmaps the buffer (see BufferObject.Unmap).
+func (b *Buffer[T]) Unmap() error {
+	return b.bo.Unmap(b.target)
+}
+
+// Len returns the number of elements in the buffer.
+func (b *Buffer[T]) Len() int {
+	return int(b.bo.GetSizeBytes()) / elementSize[T]()
+}
+
+// ElementSize returns the size of a single element in bytes.
+func (b *Buffer[T]) ElementSize() int {
+	return elementSize[T]()
+}
+
+// GetBufferObject returns the underlying buffer object.
+func (b *Buffer[T]) GetBufferObject() *BufferObject {
+	return b.bo
+}
+
+// Bind sets the current buffer of the buffer's target.
+func (b *Buffer[T]) Bind() {
+	b.bo.Bind(b.target)
+}
+
+// Unbind unsets the current buffer of the buffer's target.
+func (b *Buffer[T]) Unbind() {
+	b.bo.Unbind(b.target)
+}
+
+// BindBufferBase binds the buffer to the given binding point of target, e.g.
+// gl.UNIFORM_BUFFER.
+func (b *Buffer[T]) BindBufferBase(target, binding uint32) {
+	b.bo.BindBufferBase(target, binding)
+}
+
+// Destroy frees external resources.
+func (b *Buffer[T]) Destroy() {
+	b.bo.Destroy()
+}
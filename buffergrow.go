@@ -0,0 +1,105 @@
+package gfx
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// ErrBufferImmutable indicates that the data store of a buffer is immutable
+// and can not be reallocated.
+const ErrBufferImmutable constErr = "buffer storage is immutable"
+
+// DefaultGrowthFactor is the factor by which Reserve grows a buffer unless
+// set otherwise with SetGrowthFactor.
+const DefaultGrowthFactor = 2
+
+// SetGrowthFactor sets the factor by which Reserve grows the buffer. Factors
+// of 1 or less grow the buffer to exactly the requested size.
+func (bo *BufferObject) SetGrowthFactor(factor float64) {
+	bo.growth = factor
+}
+
+// CopyBufferSubData copies sizeBytes from readOffset in the buffer to
+// writeOffset in dst, without going through the CPU. Dst may be the buffer
+// itself if the ranges do not overlap.
+//
+// Possible errors are ErrOutOfBounds.
+func (bo *BufferObject) CopyBufferSubData(dst *BufferObject, readOffset, writeOffset, sizeBytes uint32) error {
+	if readOffset+sizeBytes > bo.sizeBytes {
+		return fmt.Errorf("%w: %v > %v", ErrOutOfBounds, readOffset+sizeBytes, bo.sizeBytes)
+	}
+	if writeOffset+sizeBytes > dst.sizeBytes {
+		return fmt.Errorf("%w: %v > %v", ErrOutOfBounds, writeOffset+sizeBytes, dst.sizeBytes)
+	}
+	if sizeBytes == 0 {
+		return nil
+	}
+	bo.Bind(gl.COPY_READ_BUFFER)
+	dst.Bind(gl.COPY_WRITE_BUFFER)
+	gl.CopyBufferSubData(gl.COPY_READ_BUFFER, gl.COPY_WRITE_BUFFER, int(readOffset), int(writeOffset), int(sizeBytes))
+	dst.Unbind(gl.COPY_WRITE_BUFFER)
+	bo.Unbind(gl.COPY_READ_BUFFER)
+	return nil
+}
+
+// Resize reallocates the data store of the buffer to sizeBytes under the same
+// ID, keeping the contents that fit. Bytes beyond the old size are undefined.
+// The buffer keeps the usage it was last allocated with.
+//
+// Possible errors are ErrBufferImmutable.
+func (bo *BufferObject) Resize(target, sizeBytes uint32) error {
+	if bo.immutable {
+		return fmt.Errorf("Resize(%v): %w", sizeBytes, ErrBufferImmutable)
+	}
+	if sizeBytes == bo.sizeBytes {
+		return nil
+	}
+	usage := bo.usage
+	if usage == 0 {
+		usage = gl.STATIC_DRAW
+	}
+	keep := bo.sizeBytes
+	if sizeBytes < keep {
+		keep = sizeBytes
+	}
+	if keep == 0 {
+		return bo.BufferData(target, sizeBytes, nil, usage)
+	}
+
+	// the data store is replaced in place, so that vertex arrays and binding
+	// points referring to the buffer stay valid
+	tmp := NewBufferObject()
+	defer tmp.Destroy()
+	if err := tmp.BufferData(gl.COPY_WRITE_BUFFER, keep, nil, gl.STREAM_COPY); err != nil {
+		return err
+	}
+	if err := bo.CopyBufferSubData(tmp, 0, 0, keep); err != nil {
+		return err
+	}
+	if err := bo.BufferData(target, sizeBytes, nil, usage); err != nil {
+		return err
+	}
+	return tmp.CopyBufferSubData(bo, 0, 0, keep)
+}
+
+// Reserve grows the data store of the buffer so that it holds at least
+// sizeBytes, keeping its contents. The size grows by the growth factor (see
+// SetGrowthFactor) so that repeated calls with increasing sizes take
+// amortized constant time. Reserve never shrinks the buffer.
+//
+// Possible errors are ErrBufferImmutable.
+func (bo *BufferObject) Reserve(target, sizeBytes uint32) error {
+	if sizeBytes <= bo.sizeBytes {
+		return nil
+	}
+	growth := bo.growth
+	if growth == 0 {
+		growth = DefaultGrowthFactor
+	}
+	grown := uint32(float64(bo.sizeBytes) * growth)
+	if grown < sizeBytes {
+		grown = sizeBytes
+	}
+	return bo.Resize(target, grown)
+}
@@ -0,0 +1,105 @@
+package gfx
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// ErrMapBuffer indicates that a buffer could not be mapped.
+const ErrMapBuffer constErr = "buffer could not be mapped"
+
+// ErrBufferCorrupt indicates that the data store of a buffer became corrupt
+// while it was mapped, and must be reinitialized.
+const ErrBufferCorrupt constErr = "buffer data corrupted while mapped"
+
+// BufferStorage creates an immutable data store for the buffer, which can not
+// be reallocated with BufferData afterwards. Flags is a combination of
+// gl.DYNAMIC_STORAGE_BIT, gl.MAP_READ_BIT, gl.MAP_WRITE_BIT,
+// gl.MAP_PERSISTENT_BIT, gl.MAP_COHERENT_BIT and gl.CLIENT_STORAGE_BIT.
+// Ptr may be nil.
+//
+// Buffer storage requires OpenGL 4.4.
+func (bo *BufferObject) BufferStorage(target uint32, sizeBytes uint32, ptr unsafe.Pointer, flags uint32) {
+	bo.sizeBytes = sizeBytes
+	bo.storageFlags = flags
+	bo.immutable = true
+	bo.Bind(target)
+	gl.BufferStorage(target, int(sizeBytes), ptr, flags)
+	bo.Unbind(target)
+}
+
+// IsImmutable reports whether the buffer was created with BufferStorage.
+func (bo *BufferObject) IsImmutable() bool {
+	return bo.immutable
+}
+
+// Map maps the whole data store of the buffer into client memory and returns
+// it as a slice. See MapRange.
+func (bo *BufferObject) Map(target, access uint32) ([]byte, error) {
+	return bo.MapRange(target, 0, bo.sizeBytes, access)
+}
+
+// MapRange maps a range of the data store of the buffer into client memory and
+// returns it as a slice. Access is a combination of gl.MAP_READ_BIT,
+// gl.MAP_WRITE_BIT and the other gl.MAP_* bits, as for glMapBufferRange.
+//
+// The slice is only valid until Unmap is called, unless the buffer was mapped
+// with gl.MAP_PERSISTENT_BIT, in which case it stays valid until Unmap even
+// while the GPU uses the buffer. The buffer stays bound to target while it is
+// mapped.
+//
+// Possible errors are ErrOutOfBounds and ErrMapBuffer.
+func (bo *BufferObject) MapRange(target, offset, length, access uint32) ([]byte, error) {
+	if offset+length > bo.sizeBytes || length == 0 {
+		return nil, fmt.Errorf("%w: %v > %v", ErrOutOfBounds, offset+length, bo.sizeBytes)
+	}
+	bo.Bind(target)
+	ptr := gl.MapBufferRange(target, int(offset), int(length), access)
+	if ptr == nil {
+		bo.Unbind(target)
+		return nil, fmt.Errorf("MapRange(%v, %v): %w", offset, length, ErrMapBuffer)
+	}
+	return unsafe.Slice((*byte)(ptr), length), nil
+}
+
+// MapPersistent maps the whole data store of a buffer created with
+// BufferStorage and gl.MAP_PERSISTENT_BIT, using the mapping bits of its
+// storage flags. The slice stays valid while the GPU uses the buffer; use
+// fences to avoid writing to regions the GPU still reads (see RegionFences).
+// Without gl.MAP_COHERENT_BIT, writes must be made visible with FlushRange.
+//
+// Possible errors are ErrOutOfBounds and ErrMapBuffer.
+func (bo *BufferObject) MapPersistent(target uint32) ([]byte, error) {
+	if bo.storageFlags&gl.MAP_PERSISTENT_BIT == 0 {
+		return nil, fmt.Errorf("MapPersistent: storage not persistent: %w", ErrMapBuffer)
+	}
+	access := bo.storageFlags & (gl.MAP_READ_BIT | gl.MAP_WRITE_BIT | gl.MAP_PERSISTENT_BIT | gl.MAP_COHERENT_BIT)
+	if access&gl.MAP_COHERENT_BIT == 0 && access&gl.MAP_WRITE_BIT != 0 {
+		access |= gl.MAP_FLUSH_EXPLICIT_BIT
+	}
+	return bo.MapRange(target, 0, bo.sizeBytes, access)
+}
+
+// FlushRange makes writes to a range of a buffer mapped with
+// gl.MAP_FLUSH_EXPLICIT_BIT visible to the GPU. Offset is relative to the
+// start of the mapped range.
+func (bo *BufferObject) FlushRange(target, offset, length uint32) {
+	bo.Bind(target)
+	gl.FlushMappedBufferRange(target, int(offset), int(length))
+}
+
+// Unmap unmaps the buffer, invalidating any slice returned by Map, MapRange
+// or MapPersistent, and unbinds it from target.
+//
+// Possible errors are ErrBufferCorrupt.
+func (bo *BufferObject) Unmap(target uint32) error {
+	bo.Bind(target)
+	ok := gl.UnmapBuffer(target)
+	bo.Unbind(target)
+	if !ok {
+		return ErrBufferCorrupt
+	}
+	return nil
+}
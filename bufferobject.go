@@ -9,8 +9,12 @@ import (
 
 // BufferObject wraps an OpenGL buffer.
 type BufferObject struct {
-	id        uint32
-	sizeBytes uint32
+	id           uint32
+	sizeBytes    uint32
+	storageFlags uint32
+	immutable    bool
+	usage        uint32
+	growth       float64
 }
 
 // NewBufferObject returns a new buffer object.
@@ -27,11 +31,19 @@ func (bo *BufferObject) GetSizeBytes() uint32 {
 }
 
 // BufferData Creates and initializes the buffer data store.
-func (bo *BufferObject) BufferData(target uint32, sizeBytes uint32, ptr unsafe.Pointer, usage uint32) {
+//
+// Possible errors are ErrBufferImmutable for buffers with immutable storage
+// (see BufferStorage), whose data store can not be reallocated.
+func (bo *BufferObject) BufferData(target uint32, sizeBytes uint32, ptr unsafe.Pointer, usage uint32) error {
+	if bo.immutable {
+		return fmt.Errorf("BufferData(%v): %w", sizeBytes, ErrBufferImmutable)
+	}
 	bo.sizeBytes = sizeBytes
+	bo.usage = usage
 	bo.Bind(target)
 	gl.BufferData(target, int(sizeBytes), ptr, usage)
 	bo.Unbind(target)
+	return nil
 }
 
 // ErrOutOfBounds indicates that the input was out of bounds.
@@ -77,9 +89,19 @@ func (bo *BufferObject) BindBufferBase(target, binding uint32) {
 	gl.BindBufferBase(target, binding, bo.id)
 }
 
+// BindBufferRange binds a range of the buffer to the given binding point of
+// target, e.g. gl.UNIFORM_BUFFER. Offset must be a multiple of the target's
+// offset alignment (see UniformBufferOffsetAlignment).
+func (bo *BufferObject) BindBufferRange(target, binding, offset, sizeBytes uint32) {
+	gl.BindBufferRange(target, binding, bo.id, int(offset), int(sizeBytes))
+}
+
 // Destroy frees external resources.
 func (bo *BufferObject) Destroy() {
 	gl.DeleteBuffers(1, &bo.id)
 	bo.id = 0
 	bo.sizeBytes = 0
+	bo.storageFlags = 0
+	bo.immutable = false
+	bo.usage = 0
 }
@@ -1,69 +1,292 @@
 package gfx
 
 import (
+	"fmt"
+	"image"
 	"unsafe"
 
 	"github.com/go-gl/gl/v2.1/gl"
 )
 
-// CubeMap wraps an OpenGL texture.
+// CubeMap wraps an OpenGL cube map texture. A CubeMap is a
+// GL_TEXTURE_CUBE_MAP_ARRAY (samplerCubeArray), even with a single layer,
+// unless it is created with NewSingleCubeMap or NewSingleCubeMapFromFaces,
+// which create a plain GL_TEXTURE_CUBE_MAP (samplerCube).
 type CubeMap struct {
-	id        uint32
-	width     int32
-	layers    int32
-	format    uint32
-	alignment int32
-	texelSize int32
+	id             uint32
+	target         uint32
+	width          int32
+	layers         int32
+	internalFormat uint32
+	format         uint32
+	alignment      int32
+	texelSize      int32
+	levels         int32
+	immutable      bool
 }
 
 // NewCubeMap creates a CubeMap object that wraps the OpenGL texture functions.
+// For each layer, data holds each row of the six faces side by side, in the
+// order of CubeFace, so that every layer is an image 6 faces wide. Data may be
+// nil.
 // For alignment, see documentation for glPixelStorei.
 // Format specifies the memory format of the data.
+//
+// Possible errors are *DataSizeError.
 func NewCubeMap(width, layers int32, data []byte, format int, alignment int32, texelSize int32) (CubeMap, error) {
+	faceData, err := stripToFaces("NewCubeMap", width, layers, data, texelSize, alignment)
+	if err != nil {
+		return CubeMap{}, err
+	}
+	return newCubeMap(gl.TEXTURE_CUBE_MAP_ARRAY, width, layers, faceData, format, alignment, texelSize)
+}
+
+// NewSingleCubeMap creates a CubeMap like NewCubeMap with a single layer,
+// which is a plain GL_TEXTURE_CUBE_MAP sampled with samplerCube. It does not
+// require cube map array support (OpenGL 4.0).
+func NewSingleCubeMap(width int32, data []byte, format int, alignment int32, texelSize int32) (CubeMap, error) {
+	faceData, err := stripToFaces("NewSingleCubeMap", width, 1, data, texelSize, alignment)
+	if err != nil {
+		return CubeMap{}, err
+	}
+	return newCubeMap(gl.TEXTURE_CUBE_MAP, width, 1, faceData, format, alignment, texelSize)
+}
+
+// NewCubeMapStorage creates a CubeMap object with immutable storage for the
+// given number of mipmap levels (see NewTextureStorage). Data is laid out as
+// for NewCubeMap, and may be nil.
+//
+// Possible errors are ErrLevelOutOfRange and *DataSizeError.
+func NewCubeMapStorage(width, layers, levels int32, internalFormat uint32, data []byte, format int, alignment int32, texelSize int32) (CubeMap, error) {
+	if levels < 1 || levels > MipLevels(width, width) {
+		return CubeMap{}, fmt.Errorf("NewCubeMapStorage(%v): %w", levels, ErrLevelOutOfRange)
+	}
+	faceData, err := stripToFaces("NewCubeMapStorage", width, layers, data, texelSize, alignment)
+	if err != nil {
+		return CubeMap{}, err
+	}
+	t := CubeMap{
+		target:         gl.TEXTURE_CUBE_MAP_ARRAY,
+		width:          width,
+		layers:         layers,
+		internalFormat: internalFormat,
+		format:         uint32(format),
+		alignment:      alignment,
+		texelSize:      texelSize,
+		levels:         levels,
+		immutable:      true,
+	}
+	t.allocate(faceData)
+	return t, nil
+}
+
+// stripToFaces reorders data holding each row of the six faces side by side
+// into each face of each layer one after another. Rows of both are padded to
+// alignment.
+func stripToFaces(op string, width, layers int32, data []byte, texelSize, alignment int32) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+	if err := checkDataSize(op, len(data), 6*width, width, layers, texelSize, alignment); err != nil {
+		return nil, err
+	}
+	rowSize := int(width) * int(texelSize)
+	stripStride := alignedRowSize(6*width, texelSize, alignment)
+	faceStride := alignedRowSize(width, texelSize, alignment)
+	faceData := make([]byte, alignedDataSize(width, width, layers*6, texelSize, alignment))
+	dst := 0
+	for l := 0; l < int(layers); l++ {
+		for i := 0; i < 6; i++ {
+			for j := 0; j < int(width); j++ {
+				start := (l*int(width)+j)*stripStride + i*rowSize
+				copy(faceData[dst:dst+rowSize], data[start:start+rowSize])
+				dst += faceStride
+			}
+		}
+	}
+	return faceData, nil
+}
+
+// newCubeMap creates a CubeMap with the given target from data holding each
+// face of each layer one after another. FaceData may be nil.
+func newCubeMap(target uint32, width, layers int32, faceData []byte, format int, alignment int32, texelSize int32) (CubeMap, error) {
 	t := CubeMap{
-		width:     width,
-		layers:    layers,
-		format:    uint32(format),
-		alignment: alignment,
-		texelSize: texelSize,
+		target:         target,
+		width:          width,
+		layers:         layers,
+		internalFormat: uint32(format),
+		format:         uint32(format),
+		alignment:      alignment,
+		texelSize:      texelSize,
+		levels:         MipLevels(width, width),
 	}
+	t.allocate(faceData)
+	return t, nil
+}
+
+// allocate creates the texture and its storage, filling the base level with
+// faceData, which may be nil.
+func (t *CubeMap) allocate(faceData []byte) {
 	gl.GenTextures(1, &t.id)
 	t.Bind()
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
-	gl.TexImage3D(gl.TEXTURE_CUBE_MAP_ARRAY, 0, int32(format), width, width, layers*6, 0, uint32(format), gl.UNSIGNED_BYTE, unsafe.Pointer(nil))
-	for l := int32(0); l < layers; l++ {
-		var faceBytes []byte
-		for i := int32(0); i < 6; i++ {
-			for j := int32(0); j < width; j++ {
-				start := (j*6 + i + l*6*width) * 4 * width
-				end := start + width*4
-				faceBytes = append(faceBytes, data[start:end]...)
+	switch {
+	case t.target == gl.TEXTURE_CUBE_MAP:
+		if t.immutable {
+			gl.TexStorage2D(gl.TEXTURE_CUBE_MAP, t.levels, t.internalFormat, t.width, t.width)
+		}
+		faceSize := alignedDataSize(t.width, t.width, 1, t.texelSize, t.alignment)
+		for i := 0; i < 6; i++ {
+			var ptr unsafe.Pointer
+			if faceData != nil {
+				ptr = unsafe.Pointer(&faceData[i*faceSize])
 			}
+			if !t.immutable {
+				gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, int32(t.internalFormat), t.width, t.width, 0, t.format, gl.UNSIGNED_BYTE, ptr)
+			} else if ptr != nil {
+				gl.TexSubImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, 0, 0, t.width, t.width, t.format, gl.UNSIGNED_BYTE, ptr)
+			}
+		}
+	case t.immutable:
+		gl.TexStorage3D(gl.TEXTURE_CUBE_MAP_ARRAY, t.levels, t.internalFormat, t.width, t.width, t.layers*6)
+		if faceData != nil {
+			gl.TexSubImage3D(gl.TEXTURE_CUBE_MAP_ARRAY, 0, 0, 0, 0, t.width, t.width, t.layers*6, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&faceData[0]))
 		}
-		gl.TexSubImage3D(gl.TEXTURE_CUBE_MAP_ARRAY, 0, 0, 0, l*6, width, width, 6, uint32(format), gl.UNSIGNED_BYTE, unsafe.Pointer(&faceBytes[0]))
+	default:
+		var ptr unsafe.Pointer
+		if faceData != nil {
+			ptr = unsafe.Pointer(&faceData[0])
+		}
+		gl.TexImage3D(gl.TEXTURE_CUBE_MAP_ARRAY, 0, int32(t.internalFormat), t.width, t.width, t.layers*6, 0, t.format, gl.UNSIGNED_BYTE, ptr)
+	}
+	if t.levels > 1 {
+		// mipmaps are generated for all faces of all layers at once
+		gl.GenerateMipmap(t.target)
 	}
-	// TODO call for every face or once per cubemap??
-	gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP_ARRAY)
 	t.Unbind()
-	return t, nil
 }
 
 // SetParameter sets the given parameter for the texture.
 func (t CubeMap) SetParameter(paramName uint32, param int32) {
 	t.Bind()
-	// TODO all 6 ?
-	gl.TexParameteri(gl.TEXTURE_CUBE_MAP_ARRAY, paramName, param)
+	gl.TexParameteri(t.target, paramName, param)
+	t.Unbind()
+}
+
+// SetFacePixelArea sets the area of a face of the given layer to the given data.
+// Each row of d must be padded to the alignment of the texture.
+//
+// Possible errors are ErrCoordOutOfRange for an invalid layer or face,
+// *AreaError and *DataSizeError.
+func (t CubeMap) SetFacePixelArea(layer int32, face CubeFace, r Rect, d []byte, genMipmap bool) error {
+	if !t.validFace(layer, face) {
+		return fmt.Errorf("SetFacePixelArea(%v, %v, %v): %w", layer, face, r, ErrCoordOutOfRange)
+	}
+	if err := checkArea("SetFacePixelArea", r.X, r.Y, 0, r.W, r.H, 1, t.width, t.width, 1); err != nil {
+		return err
+	}
+	if err := checkDataSize("SetFacePixelArea", len(d), r.W, r.H, 1, t.texelSize, t.alignment); err != nil {
+		return err
+	}
+	t.Bind()
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
+	if t.target == gl.TEXTURE_CUBE_MAP {
+		gl.TexSubImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(face), 0, r.X, r.Y, r.W, r.H, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&d[0]))
+	} else {
+		gl.TexSubImage3D(gl.TEXTURE_CUBE_MAP_ARRAY, 0, r.X, r.Y, layer*6+int32(face), r.W, r.H, 1, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&d[0]))
+	}
+	if genMipmap {
+		gl.GenerateMipmap(t.target)
+	}
+	t.Unbind()
+	return nil
+}
+
+// SetFace replaces all of the data of a face of the given layer.
+func (t CubeMap) SetFace(layer int32, face CubeFace, d []byte, genMipmap bool) error {
+	return t.SetFacePixelArea(layer, face, Rect{X: 0, Y: 0, W: t.width, H: t.width}, d, genMipmap)
+}
+
+// SetLayer replaces all of the data of the given layer. Data holds each face
+// one after another, in the order of CubeFace.
+func (t CubeMap) SetLayer(layer int32, d []byte, genMipmap bool) error {
+	faceSize := alignedDataSize(t.width, t.width, 1, t.texelSize, t.alignment)
+	if err := checkDataSize("SetLayer", len(d), t.width, t.width, 6, t.texelSize, t.alignment); err != nil {
+		return err
+	}
+	for face := CubeFacePosX; face <= CubeFaceNegZ; face++ {
+		start := int(face) * faceSize
+		if start+faceSize > len(d) {
+			// the last row of the last face may be unpadded
+			faceSize = len(d) - start
+		}
+		if err := t.SetFace(layer, face, d[start:start+faceSize], false); err != nil {
+			return err
+		}
+	}
+	if genMipmap {
+		t.GenerateMipmap()
+	}
+	return nil
+}
+
+// GenerateMipmap regenerates the mipmaps of every face of every layer.
+func (t CubeMap) GenerateMipmap() {
+	t.Bind()
+	gl.GenerateMipmap(t.target)
 	t.Unbind()
 }
 
+// GetFaceData returns a byte slice of the data of a face of the given layer.
+//
+// Possible errors are ErrCoordOutOfRange for an invalid layer or face.
+func (t CubeMap) GetFaceData(layer int32, face CubeFace) ([]byte, error) {
+	if !t.validFace(layer, face) {
+		return nil, fmt.Errorf("GetFaceData(%v, %v): %w", layer, face, ErrCoordOutOfRange)
+	}
+	var data = make([]byte, alignedDataSize(t.width, t.width, 1, t.texelSize, t.alignment))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
+	gl.GetTextureSubImage(t.id, 0, 0, 0, layer*6+int32(face), t.width, t.width, 1, t.format, gl.UNSIGNED_BYTE, int32(len(data)), unsafe.Pointer(&data[0]))
+	return data, nil
+}
+
+// validFace reports whether the texture has the given layer and face.
+func (t CubeMap) validFace(layer int32, face CubeFace) bool {
+	return layer >= 0 && layer < t.layers && face >= CubeFacePosX && face <= CubeFaceNegZ
+}
+
+// GetData returns a byte slice of all the texture data, holding each face of
+// each layer one after another.
+func (t CubeMap) GetData() []byte {
+	// TODO do this in batches/stream to avoid memory limitations
+	var data = make([]byte, alignedDataSize(t.width, t.width, t.layers*6, t.texelSize, t.alignment))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
+	gl.GetTextureImage(t.id, 0, t.format, gl.UNSIGNED_BYTE, int32(len(data)), unsafe.Pointer(&data[0]))
+	return data
+}
+
+// FaceImage returns a face of the given layer as an *image.NRGBA, top row
+// first, which is useful for inspecting dynamically rendered cube maps.
+//
+// Possible errors are ErrCoordOutOfRange for an invalid layer or face.
+func (t CubeMap) FaceImage(layer int32, face CubeFace) (image.Image, error) {
+	if !t.validFace(layer, face) {
+		return nil, fmt.Errorf("FaceImage(%v, %v): %w", layer, face, ErrCoordOutOfRange)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, int(t.width), int(t.width)))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.GetTextureSubImage(t.id, 0, 0, 0, layer*6+int32(face), t.width, t.width, 1, gl.RGBA, gl.UNSIGNED_BYTE, int32(len(img.Pix)), unsafe.Pointer(&img.Pix[0]))
+	return img, nil
+}
+
 // Bind sets this texture as the current texture.
 func (t CubeMap) Bind() {
-	gl.BindTexture(gl.TEXTURE_CUBE_MAP_ARRAY, t.id)
+	gl.BindTexture(t.target, t.id)
 }
 
 // Unbind unsets the current texture.
 func (t CubeMap) Unbind() {
-	gl.BindTexture(gl.TEXTURE_CUBE_MAP_ARRAY, 0)
+	gl.BindTexture(t.target, 0)
 }
 
 // GetWidth returns the width of the texture.
@@ -71,11 +294,26 @@ func (t CubeMap) GetWidth() int32 {
 	return t.width
 }
 
-// GetHeight returns the height of the texture.
+// GetLayers returns the number of layers of the texture.
 func (t CubeMap) GetLayers() int32 {
 	return t.layers
 }
 
+// IsArray reports whether the texture is a GL_TEXTURE_CUBE_MAP_ARRAY.
+func (t CubeMap) IsArray() bool {
+	return t.target == gl.TEXTURE_CUBE_MAP_ARRAY
+}
+
+// GetLevels returns the number of mipmap levels of the texture.
+func (t CubeMap) GetLevels() int32 {
+	return t.levels
+}
+
+// IsImmutable reports whether the texture has immutable storage.
+func (t CubeMap) IsImmutable() bool {
+	return t.immutable
+}
+
 // Destroy frees external resources.
 func (t CubeMap) Destroy() {
 	gl.DeleteTextures(1, &t.id)
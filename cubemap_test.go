@@ -0,0 +1,72 @@
+package gfx
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestStripToFaces(t *testing.T) {
+	// a 1x1 face per byte, faces side by side in a 6 byte row
+	strip := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	got, err := stripToFaces("test", 1, 2, strip, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, strip) {
+		t.Errorf("faces = %v, want %v", got, strip)
+	}
+
+	// 2x2 faces with rows padded to 4 bytes
+	strip = []byte{
+		0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5,
+		0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5,
+	}
+	got, err = stripToFaces("test", 2, 1, strip, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 0, 6*2*4)
+	for i := byte(0); i < 6; i++ {
+		want = append(want, i, i, 0, 0, i, i, 0, 0)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("faces = %v, want %v", got, want)
+	}
+
+	if got, err := stripToFaces("test", 2, 1, nil, 1, 4); got != nil || err != nil {
+		t.Errorf("nil data = %v, %v, want nil", got, err)
+	}
+	var sizeErr *DataSizeError
+	if _, err := stripToFaces("test", 2, 1, strip[:20], 1, 4); !errors.As(err, &sizeErr) {
+		t.Errorf("short data: err = %v, want *DataSizeError", err)
+	}
+}
+
+func TestEquirectFacesEmpty(t *testing.T) {
+	pano := uniformNRGBA(image.Rect(0, 0, 2, 1), testBlue)
+	tests := []struct {
+		name  string
+		img   image.Image
+		width int
+	}{
+		{"empty panorama", image.NewNRGBA(image.Rect(0, 0, 0, 0)), 4},
+		{"zero width", pano, 0},
+		{"negative width", pano, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EquirectFaces(tt.img, tt.width); !errors.Is(err, ErrEmptyData) {
+				t.Errorf("err = %v, want ErrEmptyData", err)
+			}
+		})
+	}
+	faces, err := EquirectFaces(pano, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if faces[CubeFaceNegZ].Bounds() != image.Rect(0, 0, 1, 1) {
+		t.Errorf("face bounds = %v, want 1x1", faces[CubeFaceNegZ].Bounds())
+	}
+}
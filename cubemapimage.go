@@ -0,0 +1,229 @@
+package gfx
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// CubeFace identifies a face of a cube map, in OpenGL's face order.
+type CubeFace int32
+
+const (
+	// CubeFacePosX is the +X (right) face.
+	CubeFacePosX CubeFace = iota
+	// CubeFaceNegX is the -X (left) face.
+	CubeFaceNegX
+	// CubeFacePosY is the +Y (top) face.
+	CubeFacePosY
+	// CubeFaceNegY is the -Y (bottom) face.
+	CubeFaceNegY
+	// CubeFacePosZ is the +Z (front) face.
+	CubeFacePosZ
+	// CubeFaceNegZ is the -Z (back) face.
+	CubeFaceNegZ
+)
+
+// CubeFaces holds one square image per face of a cube, indexed by CubeFace.
+type CubeFaces [6]image.Image
+
+// NewCubeMapFromFaces creates a new RGBA CubeMap with one layer per element of
+// layers. All faces must be square and have the same dimensions.
+func NewCubeMapFromFaces(layers ...CubeFaces) (CubeMap, error) {
+	return newCubeMapFromFaces(gl.TEXTURE_CUBE_MAP_ARRAY, layers)
+}
+
+// NewSingleCubeMapFromFaces creates a new RGBA CubeMap like
+// NewCubeMapFromFaces with a single layer, which is a plain
+// GL_TEXTURE_CUBE_MAP (see NewSingleCubeMap). Use CrossFaces or EquirectFaces
+// to create it from a cross layout image or panorama.
+func NewSingleCubeMapFromFaces(faces CubeFaces) (CubeMap, error) {
+	return newCubeMapFromFaces(gl.TEXTURE_CUBE_MAP, []CubeFaces{faces})
+}
+
+// newCubeMapFromFaces creates a new RGBA CubeMap with the given target.
+func newCubeMapFromFaces(target uint32, layers []CubeFaces) (CubeMap, error) {
+	if len(layers) == 0 || layers[0][0] == nil {
+		return CubeMap{}, ErrEmptyData
+	}
+	width := layers[0][0].Bounds().Dx()
+	if width <= 0 {
+		return CubeMap{}, ErrEmptyData
+	}
+	faceData := make([]byte, 0, width*width*4*6*len(layers))
+	for l, faces := range layers {
+		for i, face := range faces {
+			if face == nil || face.Bounds().Dx() != width || face.Bounds().Dy() != width {
+				return CubeMap{}, fmt.Errorf("layer %v face %v: want %vx%v: %w", l, i, width, width, ErrLayerSize)
+			}
+			faceData = append(faceData, imageToNRGBA(face)...)
+		}
+	}
+	t, err := newCubeMap(target, int32(width), int32(len(layers)), faceData, gl.RGBA, 4, 4)
+	t.SetParameter(gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
+	t.SetParameter(gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	return t, err
+}
+
+// NewCubeMapFromCross creates a new RGBA CubeMap with one layer per cross
+// layout image (see CrossFaces).
+func NewCubeMapFromCross(imgs ...image.Image) (CubeMap, error) {
+	layers := make([]CubeFaces, 0, len(imgs))
+	for _, img := range imgs {
+		faces, err := CrossFaces(img)
+		if err != nil {
+			return CubeMap{}, err
+		}
+		layers = append(layers, faces)
+	}
+	return NewCubeMapFromFaces(layers...)
+}
+
+// NewCubeMapFromEquirect creates a new RGBA CubeMap with faces of the given
+// width and one layer per equirectangular panorama (see EquirectFaces).
+func NewCubeMapFromEquirect(width int, imgs ...image.Image) (CubeMap, error) {
+	layers := make([]CubeFaces, 0, len(imgs))
+	for _, img := range imgs {
+		faces, err := EquirectFaces(img, width)
+		if err != nil {
+			return CubeMap{}, err
+		}
+		layers = append(layers, faces)
+	}
+	return NewCubeMapFromFaces(layers...)
+}
+
+// ErrCrossLayout indicates that an image does not have a cross layout.
+const ErrCrossLayout constErr = "image is not a 4x3 or 3x4 cross layout"
+
+// CrossFaces splits an image in a horizontal (4x3 faces) or vertical (3x4
+// faces) cross layout into its faces:
+//
+//	horizontal:      vertical:
+//	   +Y               +Y
+//	-X +Z +X -Z      -X +Z +X
+//	   -Y               -Y
+//	                    -Z
+//
+// In the vertical layout, the -Z face is upside down.
+func CrossFaces(img image.Image) (CubeFaces, error) {
+	b := img.Bounds()
+	var faces CubeFaces
+	var cells [6]image.Point
+	var size int
+	switch {
+	case b.Dx()*3 == b.Dy()*4:
+		size = b.Dx() / 4
+		cells = [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}
+	case b.Dx()*4 == b.Dy()*3:
+		size = b.Dx() / 3
+		cells = [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}}
+	default:
+		return CubeFaces{}, fmt.Errorf("CrossFaces(%vx%v): %w", b.Dx(), b.Dy(), ErrCrossLayout)
+	}
+	for i, cell := range cells {
+		origin := b.Min.Add(cell.Mul(size))
+		face := image.NewNRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(face, face.Bounds(), img, origin, draw.Src)
+		faces[i] = face
+	}
+	if b.Dx() < b.Dy() {
+		rotate180(faces[CubeFaceNegZ].(*image.NRGBA))
+	}
+	return faces, nil
+}
+
+// rotate180 rotates img in place.
+func rotate180(img *image.NRGBA) {
+	pix := img.Pix
+	for i, j := 0, len(pix)-4; i < j; i, j = i+4, j-4 {
+		for c := 0; c < 4; c++ {
+			pix[i+c], pix[j+c] = pix[j+c], pix[i+c]
+		}
+	}
+}
+
+// EquirectFaces resamples an equirectangular (longitude/latitude) panorama
+// into cube faces of the given width with bilinear filtering. The center of
+// the panorama maps to the -Z face.
+//
+// Possible errors are ErrEmptyData for an empty panorama or a width less than
+// one.
+func EquirectFaces(img image.Image, width int) (CubeFaces, error) {
+	b := img.Bounds()
+	panoW, panoH := b.Dx(), b.Dy()
+	if panoW <= 0 || panoH <= 0 || width <= 0 {
+		return CubeFaces{}, fmt.Errorf("EquirectFaces(%vx%v, %v): %w", panoW, panoH, width, ErrEmptyData)
+	}
+	pano := imageToNRGBA(img)
+
+	sample := func(u, v float64, out []byte) {
+		// texel centers are at half coordinates
+		u -= 0.5
+		v -= 0.5
+		x0 := int(math.Floor(u))
+		y0 := int(math.Floor(v))
+		fx := u - float64(x0)
+		fy := v - float64(y0)
+		texel := func(x, y int) []byte {
+			x = ((x % panoW) + panoW) % panoW
+			if y < 0 {
+				y = 0
+			} else if y >= panoH {
+				y = panoH - 1
+			}
+			i := (y*panoW + x) * 4
+			return pano[i : i+4]
+		}
+		t00, t10 := texel(x0, y0), texel(x0+1, y0)
+		t01, t11 := texel(x0, y0+1), texel(x0+1, y0+1)
+		for c := 0; c < 4; c++ {
+			top := float64(t00[c])*(1-fx) + float64(t10[c])*fx
+			bottom := float64(t01[c])*(1-fx) + float64(t11[c])*fx
+			out[c] = byte(math.Round(top*(1-fy) + bottom*fy))
+		}
+	}
+
+	var faces CubeFaces
+	for f := CubeFacePosX; f <= CubeFaceNegZ; f++ {
+		face := image.NewNRGBA(image.Rect(0, 0, width, width))
+		for row := 0; row < width; row++ {
+			for col := 0; col < width; col++ {
+				sc := 2*(float64(col)+0.5)/float64(width) - 1
+				tc := 2*(float64(row)+0.5)/float64(width) - 1
+				x, y, z := cubeDirection(f, sc, tc)
+				lon := math.Atan2(x, -z)
+				lat := math.Atan2(y, math.Hypot(x, z))
+				u := (lon/(2*math.Pi) + 0.5) * float64(panoW)
+				v := (0.5 - lat/math.Pi) * float64(panoH)
+				i := face.PixOffset(col, row)
+				sample(u, v, face.Pix[i:i+4])
+			}
+		}
+		faces[f] = face
+	}
+	return faces, nil
+}
+
+// cubeDirection returns the direction from the center of a cube through the
+// point of face f with face coordinates sc and tc in [-1, 1], following the
+// OpenGL cube map face selection rules.
+func cubeDirection(f CubeFace, sc, tc float64) (x, y, z float64) {
+	switch f {
+	case CubeFacePosX:
+		return 1, -tc, -sc
+	case CubeFaceNegX:
+		return -1, -tc, sc
+	case CubeFacePosY:
+		return sc, 1, tc
+	case CubeFaceNegY:
+		return sc, -1, -tc
+	case CubeFacePosZ:
+		return sc, -tc, 1
+	default:
+		return -sc, -tc, -1
+	}
+}
@@ -0,0 +1,106 @@
+package gfx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// ErrFenceWait indicates that waiting on a fence failed.
+const ErrFenceWait constErr = "fence wait failed"
+
+// Fence wraps an OpenGL sync object that is signaled once the GPU completed
+// all commands issued before it. The zero Fence is always signaled.
+type Fence struct {
+	sync uintptr
+}
+
+// NewFence inserts a new fence into the command stream.
+func NewFence() Fence {
+	return Fence{sync: gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)}
+}
+
+// Wait blocks until the fence is signaled or timeout elapsed, and reports
+// whether it was signaled. Pending commands are flushed so that the fence can
+// be signaled at all.
+//
+// Possible errors are ErrFenceWait.
+func (f Fence) Wait(timeout time.Duration) (bool, error) {
+	if f.sync == 0 {
+		return true, nil
+	}
+	switch gl.ClientWaitSync(f.sync, gl.SYNC_FLUSH_COMMANDS_BIT, uint64(timeout)) {
+	case gl.ALREADY_SIGNALED, gl.CONDITION_SATISFIED:
+		return true, nil
+	case gl.TIMEOUT_EXPIRED:
+		return false, nil
+	}
+	return false, ErrFenceWait
+}
+
+// Signaled reports whether the fence is signaled, without blocking.
+func (f Fence) Signaled() bool {
+	ok, _ := f.Wait(0)
+	return ok
+}
+
+// WaitGPU makes the GPU, rather than the caller, wait for the fence before
+// executing further commands.
+func (f Fence) WaitGPU() {
+	if f.sync != 0 {
+		gl.WaitSync(f.sync, 0, gl.TIMEOUT_IGNORED)
+	}
+}
+
+// Destroy frees external resources.
+func (f Fence) Destroy() {
+	if f.sync != 0 {
+		gl.DeleteSync(f.sync)
+	}
+}
+
+// RegionFences guards the regions of a persistently mapped buffer, so that the
+// CPU can write to one region while the GPU reads the others. After issuing
+// the commands that read a region, call Lock; before writing to it again,
+// call Wait.
+type RegionFences struct {
+	fences []Fence
+}
+
+// NewRegionFences returns fences for the given number of regions, all of
+// which are initially unlocked.
+func NewRegionFences(regions int) *RegionFences {
+	return &RegionFences{fences: make([]Fence, regions)}
+}
+
+// Lock marks the region as in use by all commands issued so far.
+func (r *RegionFences) Lock(region int) {
+	r.fences[region].Destroy()
+	r.fences[region] = NewFence()
+}
+
+// Wait blocks until the GPU finished the commands using the region, or
+// timeout elapsed, and unlocks it.
+//
+// Possible errors are ErrFenceWait, also when the timeout elapsed.
+func (r *RegionFences) Wait(region int, timeout time.Duration) error {
+	ok, err := r.fences[region].Wait(timeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("region %v: timeout after %v: %w", region, timeout, ErrFenceWait)
+	}
+	r.fences[region].Destroy()
+	r.fences[region] = Fence{}
+	return nil
+}
+
+// Destroy frees external resources.
+func (r *RegionFences) Destroy() {
+	for i := range r.fences {
+		r.fences[i].Destroy()
+		r.fences[i] = Fence{}
+	}
+}
@@ -3,11 +3,8 @@ package gfx
 import (
 	"fmt"
 	"image"
-	"image/color"
-	"image/png"
 	"io/ioutil"
 	"math"
-	"os"
 	"unicode"
 
 	"github.com/go-gl/gl/v2.1/gl"
@@ -239,10 +236,12 @@ func LoadFontTexture(fontName string, fontSize int32) (*FontInfo, error) {
 	texHeight := int32(len(glyphBytes) / glyph.Stride)
 
 	// pass glyphBytes to OpenGL texture
-	fontTexture, err := NewTexture(texWidth, texHeight, glyphBytes, gl.RED, 1, 1)
+	fontTexture, err := NewTextureLevels(texWidth, texHeight, 1, glyphBytes, gl.RED, 1, 1)
 	if err != nil {
 		return nil, err
 	}
+	// glyph rows are stored top row first
+	fontTexture.SetTopRowFirst(true)
 	fontTexture.SetParameter(gl.TEXTURE_MIN_FILTER, gl.NEAREST)
 	fontTexture.SetParameter(gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 
@@ -291,30 +290,7 @@ func (font *FontInfo) CalcStringDims(str string) (float64, float64) {
 
 // WriteFontToFile saves an image of all font characters to fileName.
 func (font *FontInfo) WriteFontToFile(fileName string) error {
-	width := int(font.texture.GetWidth())
-	height := int(font.texture.GetHeight())
-	alphaImg := image.NewAlpha(image.Rect(0, 0, width, height))
-	outImg := image.NewNRGBA(image.Rect(0, 0, width, height))
-	alphaImg.Pix = font.texture.GetData()
-	for j := 0; j < height; j++ {
-		for i := 0; i < width; i++ {
-			col := color.NRGBAModel.Convert(alphaImg.At(i, j))
-			alpha := col.(color.NRGBA).A
-			newCol := color.NRGBA{alpha, alpha, alpha, 255}
-			outImg.Set(i, j, newCol)
-		}
-	}
-	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR, 0755)
-	if err != nil {
-		return err
-	}
-	if err = png.Encode(file, outImg); err != nil {
-		return err
-	}
-	if err = file.Close(); err != nil {
-		return err
-	}
-	return nil
+	return writePNG(fileName, font.texture.Image())
 }
 
 // func writeRuneToFile(fileName string, mask image.Image, maskp image.Point, rec image.Rectangle) error {
@@ -6,8 +6,12 @@ import (
 
 // FrameBuffer wraps an OpenGL framebuffer.
 type FrameBuffer struct {
-	id  uint32
-	tex Texture
+	id   uint32
+	tex  Texture
+	srgb bool
+	// prevSRGB holds whether GL_FRAMEBUFFER_SRGB was enabled before Bind, and
+	// is shared between copies so that Unbind can restore it.
+	prevSRGB *bool
 }
 
 // ErrFrameBuffer indicates that a program failed to link.
@@ -16,14 +20,29 @@ const ErrFrameBuffer constErr = "incomplete framebuffer"
 // NewFrameBuffer creates an FBO of the specified size that renders to
 // a texture.
 func NewFrameBuffer(width, height int32) (FrameBuffer, error) {
-	var fb FrameBuffer
+	return newFrameBuffer(width, height, false)
+}
+
+// NewFrameBufferSRGB creates an FBO of the specified size that renders to an
+// sRGB texture. While it is bound, linear shader outputs are encoded to sRGB
+// and blending is done in linear space.
+func NewFrameBufferSRGB(width, height int32) (FrameBuffer, error) {
+	return newFrameBuffer(width, height, true)
+}
+
+func newFrameBuffer(width, height int32, srgb bool) (FrameBuffer, error) {
+	fb := FrameBuffer{srgb: srgb, prevSRGB: new(bool)}
 	var err error
 	gl.GenFramebuffers(1, &fb.id)
 	fb.Bind()
 	bufs := uint32(gl.COLOR_ATTACHMENT0)
 	gl.DrawBuffers(1, &bufs)
 
-	fb.tex, err = NewTexture(width, height, nil, gl.RGBA, 4, 4)
+	internalFormat := uint32(gl.RGBA)
+	if srgb {
+		internalFormat = gl.SRGB8_ALPHA8
+	}
+	fb.tex, err = newTexture(width, height, 1, nil, internalFormat, gl.RGBA, 4, 4)
 	if err != nil {
 		fb.Unbind()
 		return FrameBuffer{}, err
@@ -43,14 +62,39 @@ func (fb FrameBuffer) GetTexture() Texture {
 	return fb.tex
 }
 
-// Bind sets this framebuffer to the current framebuffer.
+// IsSRGB reports whether the frame buffer renders to an sRGB texture.
+func (fb FrameBuffer) IsSRGB() bool {
+	return fb.srgb
+}
+
+// Bind sets this framebuffer to the current framebuffer. For sRGB frame
+// buffers, this also enables GL_FRAMEBUFFER_SRGB, saving its previous state.
 func (fb FrameBuffer) Bind() {
 	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.id)
+	if fb.srgb {
+		*fb.prevSRGB = gl.IsEnabled(gl.FRAMEBUFFER_SRGB)
+		gl.Enable(gl.FRAMEBUFFER_SRGB)
+	}
 }
 
-// Unbind unsets the current framebuffer.
+// Unbind unsets the current framebuffer. For sRGB frame buffers, this also
+// restores GL_FRAMEBUFFER_SRGB to its state before Bind.
 func (fb FrameBuffer) Unbind() {
 	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if fb.srgb {
+		SetFrameBufferSRGB(*fb.prevSRGB)
+	}
+}
+
+// SetFrameBufferSRGB enables or disables GL_FRAMEBUFFER_SRGB, which encodes
+// shader outputs to sRGB when rendering to an sRGB capable framebuffer, such
+// as an sRGB default framebuffer.
+func SetFrameBufferSRGB(enable bool) {
+	if enable {
+		gl.Enable(gl.FRAMEBUFFER_SRGB)
+	} else {
+		gl.Disable(gl.FRAMEBUFFER_SRGB)
+	}
 }
 
 // Destroy frees external resources.
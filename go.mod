@@ -1,9 +1,11 @@
 module github.com/kroppt/gfx
 
-go 1.15
+go 1.18
 
 require (
 	github.com/go-gl/gl v0.0.0-20190320180904-bf2b1f2f34d7
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	golang.org/x/image v0.0.0-20200119044424-58c23975cae1
 )
+
+require golang.org/x/text v0.3.0 // indirect
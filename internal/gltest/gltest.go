@@ -0,0 +1,77 @@
+//go:build linux && cgo
+
+// Package gltest creates headless OpenGL contexts for tests, using EGL on the
+// Mesa surfaceless platform.
+package gltest
+
+/*
+#cgo LDFLAGS: -lEGL
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+
+static EGLDisplay display = EGL_NO_DISPLAY;
+static EGLContext context = EGL_NO_CONTEXT;
+
+static int makeCurrent(void) {
+	if (context == EGL_NO_CONTEXT) {
+		PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+			(PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+		if (!getPlatformDisplay) {
+			return 1;
+		}
+		display = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+		if (display == EGL_NO_DISPLAY || !eglInitialize(display, NULL, NULL)) {
+			return 2;
+		}
+		if (!eglBindAPI(EGL_OPENGL_API)) {
+			return 3;
+		}
+		EGLint attribs[] = {
+			EGL_CONTEXT_MAJOR_VERSION, 4,
+			EGL_CONTEXT_MINOR_VERSION, 5,
+			EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_COMPATIBILITY_PROFILE_BIT,
+			EGL_NONE,
+		};
+		context = eglCreateContext(display, EGL_NO_CONFIG_KHR, EGL_NO_CONTEXT, attribs);
+		if (context == EGL_NO_CONTEXT) {
+			return 4;
+		}
+	}
+	if (!eglMakeCurrent(display, EGL_NO_SURFACE, EGL_NO_SURFACE, context)) {
+		return 5;
+	}
+	return 0;
+}
+
+static void releaseCurrent(void) {
+	eglMakeCurrent(display, EGL_NO_SURFACE, EGL_NO_SURFACE, EGL_NO_CONTEXT);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Context makes an OpenGL 4.5 compatibility profile context without a window
+// current on the calling goroutine, which is locked to its thread until the
+// returned release function is called. Tests render to frame buffers.
+func Context() (release func(), err error) {
+	runtime.LockOSThread()
+	if code := C.makeCurrent(); code != 0 {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("gltest: EGL setup failed at step %v", int(code))
+	}
+	if err := gl.Init(); err != nil {
+		C.releaseCurrent()
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+	return func() {
+		C.releaseCurrent()
+		runtime.UnlockOSThread()
+	}, nil
+}
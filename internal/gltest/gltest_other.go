@@ -0,0 +1,11 @@
+//go:build !linux || !cgo
+
+package gltest
+
+import "errors"
+
+// Context returns an error, since headless contexts are only supported on
+// Linux.
+func Context() (release func(), err error) {
+	return nil, errors.New("gltest: headless contexts require Linux and cgo")
+}
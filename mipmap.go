@@ -0,0 +1,186 @@
+package gfx
+
+import (
+	"math"
+)
+
+// MipFilter selects the filter used to generate mipmaps on the CPU.
+type MipFilter int
+
+const (
+	// MipFilterBox averages each block of texels.
+	MipFilterBox MipFilter = iota
+	// MipFilterLanczos uses a 3-lobed Lanczos filter, which keeps more detail.
+	MipFilterLanczos
+	// MipFilterBoxSRGB is MipFilterBox applied to linearized sRGB color.
+	MipFilterBoxSRGB
+	// MipFilterLanczosSRGB is MipFilterLanczos applied to linearized sRGB color.
+	MipFilterLanczosSRGB
+)
+
+// MipLevels returns the number of levels in a full mipmap chain for a texture
+// of the given size.
+func MipLevels(width, height int32) int32 {
+	size := width
+	if height > size {
+		size = height
+	}
+	levels := int32(1)
+	for size > 1 {
+		size >>= 1
+		levels++
+	}
+	return levels
+}
+
+// mipSize returns the size of a dimension at the given mipmap level.
+func mipSize(size, level int32) int32 {
+	size >>= level
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// GenerateMipChain downsamples data, an image of the given size with channels
+// bytes per texel, into a mipmap chain with the given number of levels. The
+// first element of the result is data itself. The result is deterministic,
+// unlike glGenerateMipmap.
+//
+// For the sRGB filters, the last channel is treated as linear alpha when there
+// are 2 or 4 channels.
+func GenerateMipChain(width, height, channels int32, data []byte, levels int32, filter MipFilter) [][]byte {
+	kernel, radius := boxKernel, 0.5
+	if filter == MipFilterLanczos || filter == MipFilterLanczosSRGB {
+		kernel, radius = lanczosKernel, 3.0
+	}
+	srgb := filter == MipFilterBoxSRGB || filter == MipFilterLanczosSRGB
+	isAlpha := func(c int) bool {
+		return (channels == 2 || channels == 4) && c == int(channels)-1
+	}
+
+	ch := int(channels)
+	src := make([]float64, len(data))
+	for i, b := range data {
+		if srgb && !isAlpha(i%ch) {
+			src[i] = srgbToLinear(float64(b) / 255)
+		} else {
+			src[i] = float64(b) / 255
+		}
+	}
+
+	chain := [][]byte{data}
+	w, h := int(width), int(height)
+	for level := int32(1); level < levels; level++ {
+		dw, dh := int(mipSize(width, level)), int(mipSize(height, level))
+		src = resample(src, w, h, ch, dw, dh, kernel, radius)
+		w, h = dw, dh
+
+		out := make([]byte, len(src))
+		for i, v := range src {
+			if srgb && !isAlpha(i%ch) {
+				v = linearToSRGB(v)
+			}
+			out[i] = byte(math.Round(math.Max(0, math.Min(1, v)) * 255))
+		}
+		chain = append(chain, out)
+	}
+	return chain
+}
+
+func boxKernel(x float64) float64 {
+	if x > -0.5 && x <= 0.5 {
+		return 1
+	}
+	return 0
+}
+
+func lanczosKernel(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	if x <= -3 || x >= 3 {
+		return 0
+	}
+	px := math.Pi * x
+	return 3 * math.Sin(px) * math.Sin(px/3) / (px * px)
+}
+
+type contrib struct {
+	index  int
+	weight float64
+}
+
+// filterWeights computes, for each of dst output samples, the normalized
+// weights of the src input samples that contribute to it.
+func filterWeights(src, dst int, kernel func(float64) float64, radius float64) [][]contrib {
+	scale := float64(src) / float64(dst)
+	support := radius * scale
+	weights := make([][]contrib, dst)
+	for i := 0; i < dst; i++ {
+		center := (float64(i) + 0.5) * scale
+		lo := int(math.Floor(center - support))
+		hi := int(math.Ceil(center + support))
+		var sum float64
+		for j := lo; j <= hi; j++ {
+			w := kernel((float64(j) + 0.5 - center) / scale)
+			if w == 0 {
+				continue
+			}
+			idx := j
+			if idx < 0 {
+				idx = 0
+			} else if idx >= src {
+				idx = src - 1
+			}
+			weights[i] = append(weights[i], contrib{idx, w})
+			sum += w
+		}
+		for k := range weights[i] {
+			weights[i][k].weight /= sum
+		}
+	}
+	return weights
+}
+
+// resample scales an image of w*h texels with ch channels to dw*dh texels
+// using a separable filter.
+func resample(src []float64, w, h, ch, dw, dh int, kernel func(float64) float64, radius float64) []float64 {
+	tmp := make([]float64, dw*h*ch)
+	wx := filterWeights(w, dw, kernel, radius)
+	for y := 0; y < h; y++ {
+		for x := 0; x < dw; x++ {
+			for _, cb := range wx[x] {
+				for c := 0; c < ch; c++ {
+					tmp[(y*dw+x)*ch+c] += src[(y*w+cb.index)*ch+c] * cb.weight
+				}
+			}
+		}
+	}
+	dst := make([]float64, dw*dh*ch)
+	wy := filterWeights(h, dh, kernel, radius)
+	for y := 0; y < dh; y++ {
+		for _, cb := range wy[y] {
+			for x := 0; x < dw; x++ {
+				for c := 0; c < ch; c++ {
+					dst[(y*dw+x)*ch+c] += tmp[(cb.index*dw+x)*ch+c] * cb.weight
+				}
+			}
+		}
+	}
+	return dst
+}
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
@@ -0,0 +1,70 @@
+package gfx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMipLevels(t *testing.T) {
+	tests := []struct {
+		width, height int32
+		want          int32
+	}{
+		{1, 1, 1},
+		{2, 1, 2},
+		{1, 2, 2},
+		{5, 3, 3},
+		{256, 256, 9},
+		{300, 200, 9},
+	}
+	for _, tt := range tests {
+		if got := MipLevels(tt.width, tt.height); got != tt.want {
+			t.Errorf("MipLevels(%v, %v) = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateMipChainSizes(t *testing.T) {
+	data := make([]byte, 5*3*4)
+	chain := GenerateMipChain(5, 3, 4, data, MipLevels(5, 3), MipFilterBox)
+	want := []int{5 * 3 * 4, 2 * 1 * 4, 1 * 1 * 4}
+	if len(chain) != len(want) {
+		t.Fatalf("got %v levels, want %v", len(chain), len(want))
+	}
+	for i, level := range chain {
+		if len(level) != want[i] {
+			t.Errorf("level %v has %v bytes, want %v", i, len(level), want[i])
+		}
+	}
+	if &chain[0][0] != &data[0] {
+		t.Error("level 0 is not data")
+	}
+}
+
+func TestGenerateMipChain(t *testing.T) {
+	uniform := bytes.Repeat([]byte{77}, 8*8)
+	tests := []struct {
+		name          string
+		width, height int32
+		channels      int32
+		data          []byte
+		filter        MipFilter
+		want          []byte
+	}{
+		{"box", 2, 2, 1, []byte{0, 255, 255, 0}, MipFilterBox, []byte{128}},
+		{"box srgb", 2, 1, 1, []byte{0, 255}, MipFilterBoxSRGB, []byte{188}},
+		{"box srgb linear alpha", 2, 1, 2, []byte{0, 0, 255, 255}, MipFilterBoxSRGB, []byte{188, 128}},
+		{"box uniform", 8, 8, 1, uniform, MipFilterBox, []byte{77}},
+		{"lanczos uniform", 8, 8, 1, uniform, MipFilterLanczos, []byte{77}},
+		{"lanczos srgb uniform", 8, 8, 1, uniform, MipFilterLanczosSRGB, []byte{77}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := MipLevels(tt.width, tt.height)
+			chain := GenerateMipChain(tt.width, tt.height, tt.channels, tt.data, levels, tt.filter)
+			if got := chain[len(chain)-1]; !bytes.Equal(got, tt.want) {
+				t.Errorf("last level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
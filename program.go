@@ -137,6 +137,46 @@ func (p Program) UploadUniformMat4(uniformName string, data [16]float32) error {
 	return nil
 }
 
+// BindUniformBlock assigns the uniform block with the given name to the
+// uniform buffer binding point, e.g. of a UniformBuffer.
+//
+// Possible errors are ErrInvalidName.
+func (p Program) BindUniformBlock(blockName string, binding uint32) error {
+	index := gl.GetUniformBlockIndex(p.id, &[]byte(blockName + "\x00")[0])
+	if index == gl.INVALID_INDEX {
+		return fmt.Errorf("%w: \"%v\"", ErrInvalidName, blockName)
+	}
+	gl.UniformBlockBinding(p.id, index, binding)
+	return nil
+}
+
+// GetUniformBlockSize returns the size in bytes the uniform block with the
+// given name requires.
+//
+// Possible errors are ErrInvalidName.
+func (p Program) GetUniformBlockSize(blockName string) (uint32, error) {
+	index := gl.GetUniformBlockIndex(p.id, &[]byte(blockName + "\x00")[0])
+	if index == gl.INVALID_INDEX {
+		return 0, fmt.Errorf("%w: \"%v\"", ErrInvalidName, blockName)
+	}
+	var size int32
+	gl.GetActiveUniformBlockiv(p.id, index, gl.UNIFORM_BLOCK_DATA_SIZE, &size)
+	return uint32(size), nil
+}
+
+// BindStorageBlock assigns the shader storage block with the given name to the
+// shader storage buffer binding point, e.g. of a StorageBuffer.
+//
+// Possible errors are ErrInvalidName.
+func (p Program) BindStorageBlock(blockName string, binding uint32) error {
+	index := gl.GetProgramResourceIndex(p.id, gl.SHADER_STORAGE_BLOCK, &[]byte(blockName + "\x00")[0])
+	if index == gl.INVALID_INDEX {
+		return fmt.Errorf("%w: \"%v\"", ErrInvalidName, blockName)
+	}
+	gl.ShaderStorageBlockBinding(p.id, index, binding)
+	return nil
+}
+
 // Bind sets the program to the current program.
 func (p Program) Bind() {
 	gl.UseProgram(p.id)
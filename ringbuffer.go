@@ -0,0 +1,182 @@
+package gfx
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// ErrRingBufferFull indicates that the region of the current frame of a
+// RingBuffer has no space left.
+const ErrRingBufferFull constErr = "ring buffer frame region full"
+
+// UniformBufferOffsetAlignment returns the alignment required for offsets
+// of uniform buffer binding ranges.
+func UniformBufferOffsetAlignment() uint32 {
+	var alignment int32
+	gl.GetIntegerv(gl.UNIFORM_BUFFER_OFFSET_ALIGNMENT, &alignment)
+	return uint32(alignment)
+}
+
+// RingBuffer sub-allocates per-frame data, such as dynamic vertices or
+// uniforms, from a single buffer. The buffer is split into one region per
+// frame in flight; each frame writes to the next region after waiting for
+// the GPU to finish the frame that last used it.
+//
+// On OpenGL 4.4 and up the buffer is persistently mapped, and data is copied
+// directly into it. Otherwise a single region is used, which is orphaned at
+// the start of every frame and written with glBufferSubData.
+//
+// Vertices written to a RingBuffer for gl.ARRAY_BUFFER are drawn by pointing
+// a VAO at them, instead of re-uploading them with VAO.Load:
+//
+//	ring.BeginFrame(time.Second)
+//	offset, err := WriteSlice(ring, vertices)
+//	...
+//	err = vao.SetVertexSource(0, ring.GetBufferObject(), offset)
+//	...
+//	err = vao.DrawRange(0, vertexCount)
+//	ring.EndFrame()
+type RingBuffer struct {
+	bo         *BufferObject
+	target     uint32
+	regionSize uint32
+	regions    int
+	alignment  uint32
+	region     int
+	head       uint32
+	mapped     []byte
+	fences     *RegionFences
+}
+
+// NewRingBuffer creates a RingBuffer for target (e.g. gl.ARRAY_BUFFER) with
+// frameSize bytes per frame and the given number of frames in flight,
+// usually 2 or 3. Every allocation starts at a multiple of alignment, e.g.
+// UniformBufferOffsetAlignment() for uniform data or the vertex size for
+// vertices. FrameSize is rounded up to a multiple of alignment, so that every
+// frame starts aligned as well.
+//
+// Possible errors are ErrMapBuffer.
+func NewRingBuffer(target, frameSize uint32, frames int, alignment uint32) (*RingBuffer, error) {
+	if alignment == 0 {
+		alignment = 1
+	}
+	frameSize = uint32(roundUp(int(frameSize), int(alignment)))
+	r := &RingBuffer{
+		bo:         NewBufferObject(),
+		target:     target,
+		regionSize: frameSize,
+		regions:    1,
+		alignment:  alignment,
+	}
+	if frames > 1 && glVersionAtLeast(4, 4) {
+		r.regions = frames
+		r.bo.BufferStorage(target, frameSize*uint32(frames), nil,
+			gl.MAP_WRITE_BIT|gl.MAP_PERSISTENT_BIT|gl.MAP_COHERENT_BIT)
+		mapped, err := r.bo.MapPersistent(target)
+		if err != nil {
+			r.bo.Destroy()
+			return nil, err
+		}
+		r.bo.Unbind(target)
+		r.mapped = mapped
+		r.fences = NewRegionFences(frames)
+	} else {
+		if err := r.bo.BufferData(target, frameSize, nil, gl.STREAM_DRAW); err != nil {
+			r.bo.Destroy()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// BeginFrame starts writing to the next region, waiting at most timeout for
+// the GPU to release it.
+//
+// Possible errors are ErrFenceWait.
+func (r *RingBuffer) BeginFrame(timeout time.Duration) error {
+	r.head = 0
+	if r.mapped == nil {
+		// orphan the data store, so the driver can hand out new memory while
+		// the GPU still reads the old one
+		return r.bo.BufferData(r.target, r.regionSize, nil, gl.STREAM_DRAW)
+	}
+	r.region = (r.region + 1) % r.regions
+	return r.fences.Wait(r.region, timeout)
+}
+
+// EndFrame marks the current region as in use by the commands issued during
+// the frame. It must be called after the last draw call using the frame's
+// data.
+func (r *RingBuffer) EndFrame() {
+	if r.fences != nil {
+		r.fences.Lock(r.region)
+	}
+}
+
+// Alloc reserves sizeBytes in the current frame's region and returns their
+// offset within the buffer.
+//
+// Possible errors are ErrRingBufferFull.
+func (r *RingBuffer) Alloc(sizeBytes uint32) (uint32, error) {
+	head := (r.head + r.alignment - 1) / r.alignment * r.alignment
+	if head+sizeBytes > r.regionSize {
+		return 0, fmt.Errorf("Alloc(%v): %v of %v bytes used: %w", sizeBytes, r.head, r.regionSize, ErrRingBufferFull)
+	}
+	r.head = head + sizeBytes
+	return uint32(r.region)*r.regionSize + head, nil
+}
+
+// Write copies sizeBytes from ptr into the current frame's region and returns
+// their offset within the buffer, for use with VAO.SetVertexSource or
+// BindRange.
+//
+// Possible errors are ErrRingBufferFull.
+func (r *RingBuffer) Write(ptr unsafe.Pointer, sizeBytes uint32) (uint32, error) {
+	offset, err := r.Alloc(sizeBytes)
+	if err != nil || sizeBytes == 0 {
+		return offset, err
+	}
+	if r.mapped != nil {
+		copy(r.mapped[offset:offset+sizeBytes], unsafe.Slice((*byte)(ptr), sizeBytes))
+		return offset, nil
+	}
+	return offset, r.bo.BufferSubData(r.target, offset, sizeBytes, ptr)
+}
+
+// WriteSlice copies data into the current frame's region of r and returns its
+// offset within the buffer (see RingBuffer.Write).
+func WriteSlice[T any](r *RingBuffer, data []T) (uint32, error) {
+	return r.Write(slicePtr(data), uint32(len(data)*elementSize[T]()))
+}
+
+// BindRange binds sizeBytes at offset to the given binding point of target,
+// e.g. gl.UNIFORM_BUFFER, for an allocation returned by Alloc or Write.
+func (r *RingBuffer) BindRange(target, binding, offset, sizeBytes uint32) {
+	r.bo.BindBufferRange(target, binding, offset, sizeBytes)
+}
+
+// GetBufferObject returns the underlying buffer object.
+func (r *RingBuffer) GetBufferObject() *BufferObject {
+	return r.bo
+}
+
+// GetFrameSize returns the size of a frame's region in bytes, which is a
+// multiple of the alignment.
+func (r *RingBuffer) GetFrameSize() uint32 {
+	return r.regionSize
+}
+
+// Destroy frees external resources.
+func (r *RingBuffer) Destroy() {
+	if r.mapped != nil {
+		_ = r.bo.Unmap(r.target)
+		r.mapped = nil
+	}
+	if r.fences != nil {
+		r.fences.Destroy()
+	}
+	r.bo.Destroy()
+}
@@ -0,0 +1,155 @@
+package gfx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/kroppt/gfx/internal/gltest"
+)
+
+// withContext runs f with a headless OpenGL context current, or skips the
+// test if none can be created.
+func withContext(t *testing.T, f func()) {
+	release, err := gltest.Context()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer release()
+	f()
+}
+
+// newTestProgram returns a program drawing vertices with a vec3 position at
+// location 0 and a vec3 color at location 1.
+func newTestProgram(t *testing.T) Program {
+	vs, err := NewShader(SampleCubeVertex, gl.VERTEX_SHADER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vs.Destroy()
+	fs, err := NewShader(SampleCubeFragment, gl.FRAGMENT_SHADER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Destroy()
+	p, err := NewProgram(vs, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// fullScreenTriangle returns the vertices of a triangle covering the viewport
+// in the given color.
+func fullScreenTriangle(r, g, b float32) []float32 {
+	return []float32{
+		-1, -1, 0, r, g, b,
+		3, -1, 0, r, g, b,
+		-1, 3, 0, r, g, b,
+	}
+}
+
+// drawPixel draws vao into fb and returns the color of its first texel.
+func drawPixel(t *testing.T, fb FrameBuffer, p Program, draw func() error) [4]byte {
+	fb.Bind()
+	gl.Viewport(0, 0, fb.GetTexture().GetWidth(), fb.GetTexture().GetHeight())
+	p.Bind()
+	err := draw()
+	p.Unbind()
+	fb.Unbind()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var px [4]byte
+	copy(px[:], fb.GetTexture().GetData())
+	return px
+}
+
+func TestRingBufferVertexSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames int
+	}{
+		{"orphaned", 1},
+		{"persistent", 3},
+	}
+	colors := [][3]float32{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 1, 0}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withContext(t, func() {
+				fb, err := NewFrameBuffer(4, 4)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer fb.Destroy()
+				p := newTestProgram(t)
+				defer p.Destroy()
+				vao := NewVAO(gl.TRIANGLES, []int32{3, 3})
+				defer vao.Destroy()
+
+				const stride = 6 * 4
+				ring, err := NewRingBuffer(gl.ARRAY_BUFFER, 200, tt.frames, stride)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer ring.Destroy()
+				if got := ring.GetFrameSize(); got != 216 {
+					t.Errorf("frame size = %v, want 216", got)
+				}
+
+				var last uint32
+				for frame, c := range colors {
+					if err := ring.BeginFrame(time.Second); err != nil {
+						t.Fatal(err)
+					}
+					// start the vertices past the start of the region
+					if _, err := ring.Alloc(stride); err != nil {
+						t.Fatal(err)
+					}
+					offset, err := WriteSlice(ring, fullScreenTriangle(c[0], c[1], c[2]))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if offset%ring.GetFrameSize() != stride || (tt.frames > 1 && offset == last) {
+						t.Errorf("frame %v: offset = %v", frame, offset)
+					}
+					last = offset
+					if err := vao.SetVertexSource(0, ring.GetBufferObject(), offset); err != nil {
+						t.Fatal(err)
+					}
+					got := drawPixel(t, fb, p, func() error { return vao.DrawRange(0, 3) })
+					ring.EndFrame()
+					want := [4]byte{byte(c[0] * 255), byte(c[1] * 255), byte(c[2] * 255), 255}
+					if got != want {
+						t.Errorf("frame %v: pixel = %v, want %v", frame, got, want)
+					}
+				}
+
+				// the VAO's own buffer is used again after resetting the source
+				if err := vao.SetVertexSource(0, nil, 0); err != nil {
+					t.Fatal(err)
+				}
+				if err := vao.Load(fullScreenTriangle(1, 1, 1), gl.STATIC_DRAW); err != nil {
+					t.Fatal(err)
+				}
+				got := drawPixel(t, fb, p, func() error { vao.Draw(); return nil })
+				if want := [4]byte{255, 255, 255, 255}; got != want {
+					t.Errorf("own buffer: pixel = %v, want %v", got, want)
+				}
+				if code := gl.GetError(); code != gl.NO_ERROR {
+					t.Errorf("GL error 0x%X", code)
+				}
+			})
+		})
+	}
+}
+
+func TestSetVertexSourceOutOfBounds(t *testing.T) {
+	withContext(t, func() {
+		vao := NewVAO(gl.TRIANGLES, []int32{3})
+		defer vao.Destroy()
+		if err := vao.SetVertexSource(1, nil, 0); err == nil {
+			t.Error("SetVertexSource(1) on a single buffer VAO succeeded")
+		}
+	})
+}
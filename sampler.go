@@ -0,0 +1,171 @@
+package gfx
+
+import (
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Filter is a texture minification or magnification filter.
+type Filter int32
+
+const (
+	// FilterNearest samples the nearest texel.
+	FilterNearest Filter = gl.NEAREST
+	// FilterLinear interpolates between the nearest texels.
+	FilterLinear Filter = gl.LINEAR
+	// FilterNearestMipmapNearest samples the nearest texel of the nearest
+	// mipmap level. Only valid for minification.
+	FilterNearestMipmapNearest Filter = gl.NEAREST_MIPMAP_NEAREST
+	// FilterLinearMipmapNearest interpolates texels of the nearest mipmap
+	// level. Only valid for minification.
+	FilterLinearMipmapNearest Filter = gl.LINEAR_MIPMAP_NEAREST
+	// FilterNearestMipmapLinear interpolates between the nearest texels of
+	// the two nearest mipmap levels. Only valid for minification.
+	FilterNearestMipmapLinear Filter = gl.NEAREST_MIPMAP_LINEAR
+	// FilterLinearMipmapLinear interpolates texels of the two nearest mipmap
+	// levels (trilinear filtering). Only valid for minification.
+	FilterLinearMipmapLinear Filter = gl.LINEAR_MIPMAP_LINEAR
+)
+
+// Wrap is a texture coordinate wrap mode.
+type Wrap int32
+
+const (
+	// WrapRepeat tiles the texture.
+	WrapRepeat Wrap = gl.REPEAT
+	// WrapMirroredRepeat tiles the texture, mirroring every other tile.
+	WrapMirroredRepeat Wrap = gl.MIRRORED_REPEAT
+	// WrapClampToEdge repeats the edge texels.
+	WrapClampToEdge Wrap = gl.CLAMP_TO_EDGE
+	// WrapClampToBorder uses the border color outside of the texture.
+	WrapClampToBorder Wrap = gl.CLAMP_TO_BORDER
+)
+
+// CompareFunc is a depth comparison function.
+type CompareFunc int32
+
+const (
+	// CompareLessEqual passes if the reference is <= the texel.
+	CompareLessEqual CompareFunc = gl.LEQUAL
+	// CompareGreaterEqual passes if the reference is >= the texel.
+	CompareGreaterEqual CompareFunc = gl.GEQUAL
+	// CompareLess passes if the reference is < the texel.
+	CompareLess CompareFunc = gl.LESS
+	// CompareGreater passes if the reference is > the texel.
+	CompareGreater CompareFunc = gl.GREATER
+	// CompareEqual passes if the reference is == the texel.
+	CompareEqual CompareFunc = gl.EQUAL
+	// CompareNotEqual passes if the reference is != the texel.
+	CompareNotEqual CompareFunc = gl.NOTEQUAL
+	// CompareAlways always passes.
+	CompareAlways CompareFunc = gl.ALWAYS
+	// CompareNever never passes.
+	CompareNever CompareFunc = gl.NEVER
+)
+
+// SamplerState describes how a texture is sampled. Zero filter, wrap and
+// compare function fields select the OpenGL default, and a zero
+// MaxAnisotropy disables anisotropic filtering.
+type SamplerState struct {
+	MinFilter     Filter
+	MagFilter     Filter
+	WrapS         Wrap
+	WrapT         Wrap
+	WrapR         Wrap
+	MaxAnisotropy float32
+	LODBias       float32
+	// Compare enables depth comparison with CompareFunc, for shadow samplers.
+	Compare     bool
+	CompareFunc CompareFunc
+	BorderColor [4]float32
+}
+
+// Sampler wraps an OpenGL sampler object. A sampler bound to a texture unit
+// overrides the sampling parameters of any texture bound to the same unit.
+type Sampler struct {
+	id uint32
+}
+
+// NewSampler creates a Sampler with the given state.
+func NewSampler(state SamplerState) Sampler {
+	var s Sampler
+	gl.GenSamplers(1, &s.id)
+	s.SetState(state)
+	return s
+}
+
+// SetState sets every parameter of the sampler to the given state, replacing
+// the previous state entirely.
+//
+// Anisotropic filtering requires OpenGL 4.6 or
+// EXT_texture_filter_anisotropic; without either, MaxAnisotropy is ignored.
+func (s Sampler) SetState(state SamplerState) {
+	gl.SamplerParameteri(s.id, gl.TEXTURE_MIN_FILTER, int32(orFilter(state.MinFilter, FilterNearestMipmapLinear)))
+	gl.SamplerParameteri(s.id, gl.TEXTURE_MAG_FILTER, int32(orFilter(state.MagFilter, FilterLinear)))
+	gl.SamplerParameteri(s.id, gl.TEXTURE_WRAP_S, int32(orWrap(state.WrapS)))
+	gl.SamplerParameteri(s.id, gl.TEXTURE_WRAP_T, int32(orWrap(state.WrapT)))
+	gl.SamplerParameteri(s.id, gl.TEXTURE_WRAP_R, int32(orWrap(state.WrapR)))
+	if anisotropySupported() {
+		anisotropy := state.MaxAnisotropy
+		if anisotropy < 1 {
+			anisotropy = 1
+		}
+		gl.SamplerParameterf(s.id, gl.TEXTURE_MAX_ANISOTROPY, anisotropy)
+	}
+	gl.SamplerParameterf(s.id, gl.TEXTURE_LOD_BIAS, state.LODBias)
+	compareMode := int32(gl.NONE)
+	if state.Compare {
+		compareMode = gl.COMPARE_R_TO_TEXTURE
+	}
+	gl.SamplerParameteri(s.id, gl.TEXTURE_COMPARE_MODE, compareMode)
+	compareFunc := state.CompareFunc
+	if compareFunc == 0 {
+		compareFunc = CompareLessEqual
+	}
+	gl.SamplerParameteri(s.id, gl.TEXTURE_COMPARE_FUNC, int32(compareFunc))
+	gl.SamplerParameterfv(s.id, gl.TEXTURE_BORDER_COLOR, &state.BorderColor[0])
+}
+
+// anisotropySupported reports whether the context supports
+// GL_TEXTURE_MAX_ANISOTROPY.
+func anisotropySupported() bool {
+	return glVersionAtLeast(4, 6) ||
+		glExtension("GL_EXT_texture_filter_anisotropic") ||
+		glExtension("GL_ARB_texture_filter_anisotropic")
+}
+
+// orFilter returns f, or def if f is zero.
+func orFilter(f, def Filter) Filter {
+	if f == 0 {
+		return def
+	}
+	return f
+}
+
+// orWrap returns w, or the default WrapRepeat if w is zero.
+func orWrap(w Wrap) Wrap {
+	if w == 0 {
+		return WrapRepeat
+	}
+	return w
+}
+
+// SetParameter sets the given parameter for the sampler.
+func (s Sampler) SetParameter(paramName uint32, param int32) {
+	gl.SamplerParameteri(s.id, paramName, param)
+}
+
+// Bind sets this sampler as the sampler of the given texture unit,
+// e.g. 0 for gl.TEXTURE0.
+func (s Sampler) Bind(unit uint32) {
+	gl.BindSampler(unit, s.id)
+}
+
+// Unbind unsets the sampler of the given texture unit.
+func (s Sampler) Unbind(unit uint32) {
+	gl.BindSampler(unit, 0)
+}
+
+// Destroy frees external resources.
+func (s Sampler) Destroy() {
+	gl.DeleteSamplers(1, &s.id)
+}
@@ -0,0 +1,134 @@
+package gfx
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// StorageBuffer holds an array of T with std430 layout in a shader storage
+// buffer, such as the runtime-sized array of a block like
+//
+//	layout(std430, binding = 0) buffer Particles { Particle particles[]; };
+//
+// Elements are encoded and decoded as by EncodeBlock and DecodeBlock.
+//
+// Shader storage buffers require OpenGL 4.3.
+type StorageBuffer[T any] struct {
+	bo      *BufferObject
+	binding uint32
+	usage   uint32
+	stride  int
+}
+
+// NewStorageBuffer creates a StorageBuffer holding data, allocated with the
+// given usage (e.g. gl.DYNAMIC_COPY), and binds it to the given binding point.
+//
+// Possible errors are ErrBlockType.
+func NewStorageBuffer[T any](data []T, binding, usage uint32) (*StorageBuffer[T], error) {
+	stride, err := BlockStride[T](Std430)
+	if err != nil {
+		return nil, err
+	}
+	s := &StorageBuffer[T]{
+		bo:      NewBufferObject(),
+		binding: binding,
+		usage:   usage,
+		stride:  stride,
+	}
+	if err := s.Upload(data); err != nil {
+		s.bo.Destroy()
+		return nil, err
+	}
+	s.Bind()
+	return s, nil
+}
+
+// Upload replaces the contents of the buffer with data, reallocating it to the
+// length of data.
+//
+// Possible errors are ErrBlockType and ErrBufferImmutable.
+func (s *StorageBuffer[T]) Upload(data []T) error {
+	buf, err := EncodeSlice(Std430, data)
+	if err != nil {
+		return err
+	}
+	return s.bo.BufferData(gl.SHADER_STORAGE_BUFFER, uint32(len(buf)), slicePtr(buf), s.usage)
+}
+
+// Update overwrites the elements starting at element offset with data.
+//
+// Possible errors are ErrBlockType and ErrOutOfBounds.
+func (s *StorageBuffer[T]) Update(offset int, data []T) error {
+	if offset < 0 || offset+len(data) > s.Len() {
+		return fmt.Errorf("Update(%v, %v): %w: buffer length %v", offset, len(data), ErrOutOfBounds, s.Len())
+	}
+	buf, err := EncodeSlice(Std430, data)
+	if err != nil || len(buf) == 0 {
+		return err
+	}
+	return s.bo.BufferSubData(gl.SHADER_STORAGE_BUFFER, uint32(offset*s.stride), uint32(len(buf)), slicePtr(buf))
+}
+
+// Read returns all elements of the buffer, e.g. the results of a compute
+// shader. Use a memory barrier with gl.BUFFER_UPDATE_BARRIER_BIT after the
+// shader writes and before reading.
+//
+// Possible errors are ErrBlockType.
+func (s *StorageBuffer[T]) Read() ([]T, error) {
+	return s.ReadRange(0, s.Len())
+}
+
+// ReadRange returns count elements starting at element offset.
+//
+// Possible errors are ErrBlockType and ErrOutOfBounds.
+func (s *StorageBuffer[T]) ReadRange(offset, count int) ([]T, error) {
+	if offset < 0 || count < 0 || offset+count > s.Len() {
+		return nil, fmt.Errorf("ReadRange(%v, %v): %w: buffer length %v", offset, count, ErrOutOfBounds, s.Len())
+	}
+	buf := make([]byte, count*s.stride)
+	if count > 0 {
+		s.bo.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, uint32(offset*s.stride), uint32(len(buf)), slicePtr(buf))
+	}
+	return DecodeSlice[T](Std430, buf)
+}
+
+// Len returns the number of elements in the buffer.
+func (s *StorageBuffer[T]) Len() int {
+	return int(s.bo.GetSizeBytes()) / s.stride
+}
+
+// Stride returns the size of an element in the buffer in bytes, including
+// padding.
+func (s *StorageBuffer[T]) Stride() int {
+	return s.stride
+}
+
+// BindToBlock assigns the shader storage block with the given name in p to
+// the binding point of the buffer.
+//
+// Possible errors are ErrInvalidName.
+func (s *StorageBuffer[T]) BindToBlock(p Program, blockName string) error {
+	return p.BindStorageBlock(blockName, s.binding)
+}
+
+// Bind binds the buffer to its binding point again, e.g. after another buffer
+// was bound to it.
+func (s *StorageBuffer[T]) Bind() {
+	s.bo.BindBufferBase(gl.SHADER_STORAGE_BUFFER, s.binding)
+}
+
+// GetBinding returns the binding point of the buffer.
+func (s *StorageBuffer[T]) GetBinding() uint32 {
+	return s.binding
+}
+
+// GetBufferObject returns the underlying buffer object.
+func (s *StorageBuffer[T]) GetBufferObject() *BufferObject {
+	return s.bo
+}
+
+// Destroy frees external resources.
+func (s *StorageBuffer[T]) Destroy() {
+	s.bo.Destroy()
+}
@@ -12,31 +12,111 @@ import (
 
 // Texture wraps an OpenGL texture.
 type Texture struct {
-	id        uint32
-	width     int32
-	height    int32
-	format    uint32
-	alignment int32
-	texelSize int32
+	id             uint32
+	width          int32
+	height         int32
+	internalFormat uint32
+	format         uint32
+	alignment      int32
+	texelSize      int32
+	levels         int32
+	alpha          AlphaMode
+	immutable      bool
+	// topRowFirst is set if the data holds the top row of an image first,
+	// rather than the bottom row as rendered by OpenGL.
+	topRowFirst bool
 }
 
 // NewTextureFromFile creates a new Texture, loading data from fileName
 // with the assumption that it is an image that can be converted to RGBA
 // (alpha is black for jpegs).
 //
+// Color images are stored in an sRGB internal format, so that sampling them
+// returns linear values. Grayscale images, which usually hold masks or height
+// data, are stored as linear data.
+//
 // To provide support for loading different image types, blank import the
 // respective image/* packages.
 func NewTextureFromFile(fileName string) (Texture, error) {
-	in, err := os.Open(fileName)
+	return NewTextureFromFileOptions(fileName, TextureOptions{})
+}
+
+// NewTextureFromFileLinear is like NewTextureFromFile, but always stores the
+// image as linear data, as is needed for e.g. normal maps.
+func NewTextureFromFileLinear(fileName string) (Texture, error) {
+	return NewTextureFromFileOptions(fileName, TextureOptions{Linear: true})
+}
+
+// TextureOptions controls how an image file is loaded into a Texture.
+type TextureOptions struct {
+	// Linear stores color images as linear data instead of sRGB.
+	Linear bool
+	// Alpha is the alpha mode the image data is converted to. Use
+	// Alpha.BlendFunc for the matching blend function. sRGB images are
+	// premultiplied in linear space (see PremultiplySRGB).
+	Alpha AlphaMode
+}
+
+// NewTextureFromFileOptions is like NewTextureFromFile, with the given options.
+func NewTextureFromFileOptions(fileName string, opts TextureOptions) (Texture, error) {
+	img, err := loadImage(fileName)
 	if err != nil {
 		return Texture{}, err
 	}
+	return newTextureFromImage(img, opts)
+}
+
+// newTextureFromImage creates a new RGBA Texture from img.
+func newTextureFromImage(img image.Image, opts TextureOptions) (Texture, error) {
+	width := int32(img.Bounds().Dx())
+	height := int32(img.Bounds().Dy())
+	data := imageToNRGBA(img)
+	srgb := !opts.Linear && isColorImage(img)
+	if opts.Alpha == AlphaPremultiplied {
+		premultiply(data, srgb)
+	}
+	var t Texture
+	var err error
+	if srgb {
+		t, err = NewTextureSRGB(width, height, data, gl.RGBA, 4, 4)
+	} else {
+		t, err = NewTexture(width, height, data, gl.RGBA, 4, 4)
+	}
+	t.alpha = opts.Alpha
+	t.topRowFirst = true
+	t.SetParameter(gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_NEAREST)
+	t.SetParameter(gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	return t, err
+}
+
+// isColorImage reports whether img holds color, rather than grayscale or
+// alpha only, data.
+func isColorImage(img image.Image) bool {
+	switch img.ColorModel() {
+	case color.GrayModel, color.Gray16Model, color.AlphaModel, color.Alpha16Model:
+		return false
+	}
+	return true
+}
+
+// loadImage opens and decodes the image stored in fileName.
+func loadImage(fileName string) (image.Image, error) {
+	in, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
 	defer in.Close()
 
 	img, _, err := image.Decode(in)
 	if err != nil {
-		return Texture{}, err
+		return nil, err
 	}
+	return img, nil
+}
+
+// imageToNRGBA converts img into tightly packed, non-premultiplied RGBA bytes
+// (alpha is opaque for images without an alpha channel).
+func imageToNRGBA(img image.Image) []byte {
 	// TODO load from underlying arrays directly and correctly format in OpenGL
 	// switch img.(type) {
 	// case *image.Alpha:
@@ -52,47 +132,143 @@ func NewTextureFromFile(fileName string) (Texture, error) {
 	// case *image.YCbCr, *image.NYCbCrA, *image.Uniform:
 	// 	// no Pix array
 	// }
-	width := img.Bounds().Dx()
-	height := img.Bounds().Dy()
-	data := make([]byte, 0, width*height*4)
-	for j := 0; j < height; j++ {
-		for i := 0; i < width; i++ {
+	bounds := img.Bounds()
+	data := make([]byte, 0, bounds.Dx()*bounds.Dy()*4)
+	for j := bounds.Min.Y; j < bounds.Max.Y; j++ {
+		for i := bounds.Min.X; i < bounds.Max.X; i++ {
 			col := color.NRGBAModel.Convert(img.At(i, j))
 			nrgba := col.(color.NRGBA)
 			r, g, b, a := nrgba.R, nrgba.G, nrgba.B, nrgba.A
 			data = append(data, r, g, b, a)
 		}
 	}
-	t, err := NewTexture(int32(width), int32(height), data, gl.RGBA, 4, 4)
-	t.SetParameter(gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_NEAREST)
-	t.SetParameter(gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-	return t, err
+	return data
 }
 
 // NewTexture creates a Texture object that wraps the OpenGL texture functions.
+// It has a full mipmap chain, which is generated from data.
 // For alignment, see documentation for glPixelStorei.
 // Format specifies the memory format of the data.
 func NewTexture(width, height int32, data []byte, format int, alignment int32, texelSize int32) (Texture, error) {
+	return NewTextureLevels(width, height, MipLevels(width, height), data, format, alignment, texelSize)
+}
+
+// ErrLevelOutOfRange indicates that a mipmap level is out of range.
+const ErrLevelOutOfRange constErr = "mipmap level out of range"
+
+// NewTextureLevels creates a Texture object with the given number of mipmap
+// levels. If there is more than one level and data is not nil, the mipmaps are
+// generated from data.
+// For alignment, see documentation for glPixelStorei.
+// Format specifies the memory format of the data.
+func NewTextureLevels(width, height, levels int32, data []byte, format int, alignment int32, texelSize int32) (Texture, error) {
+	return newTexture(width, height, levels, data, uint32(format), uint32(format), alignment, texelSize)
+}
+
+// ErrNoSRGBFormat indicates that a format has no sRGB equivalent.
+const ErrNoSRGBFormat constErr = "format has no sRGB internal format"
+
+// NewTextureSRGB creates a Texture object like NewTexture, but with data in
+// the sRGB color space, which the GPU converts to linear values when sampling.
+// Format must be gl.RGB or gl.RGBA.
+func NewTextureSRGB(width, height int32, data []byte, format int, alignment int32, texelSize int32) (Texture, error) {
+	internalFormat, err := srgbInternalFormat(uint32(format))
+	if err != nil {
+		return Texture{}, err
+	}
+	return newTexture(width, height, MipLevels(width, height), data, internalFormat, uint32(format), alignment, texelSize)
+}
+
+// srgbInternalFormat returns the sRGB internal format matching format.
+func srgbInternalFormat(format uint32) (uint32, error) {
+	switch format {
+	case gl.RGBA:
+		return gl.SRGB8_ALPHA8, nil
+	case gl.RGB:
+		return gl.SRGB8, nil
+	}
+	return 0, fmt.Errorf("0x%X: %w", format, ErrNoSRGBFormat)
+}
+
+// ErrImmutable indicates that the storage of a texture is immutable.
+const ErrImmutable constErr = "texture storage is immutable"
+
+// NewTextureStorage creates a Texture object with immutable storage for the
+// given number of mipmap levels, allocated with glTexStorage2D. Immutable
+// storage lets the driver skip completeness checks and is required for
+// texture views. InternalFormat must be a sized format, e.g. gl.RGBA8.
+// Data may be nil; otherwise it fills the base level and the mipmaps are
+// generated from it.
+// For alignment, see documentation for glPixelStorei.
+// Format specifies the memory format of the data.
+func NewTextureStorage(width, height, levels int32, internalFormat uint32, data []byte, format int, alignment int32, texelSize int32) (Texture, error) {
+	if levels < 1 || levels > MipLevels(width, height) {
+		return Texture{}, fmt.Errorf("NewTextureStorage(%v): %w", levels, ErrLevelOutOfRange)
+	}
+	t := Texture{
+		width:          width,
+		height:         height,
+		internalFormat: internalFormat,
+		format:         uint32(format),
+		alignment:      alignment,
+		texelSize:      texelSize,
+		levels:         levels,
+		immutable:      true,
+	}
+	gl.GenTextures(1, &t.id)
+	t.allocate(data)
+
+	return t, nil
+}
+
+// newTexture creates a Texture object with separate internal and data formats.
+func newTexture(width, height, levels int32, data []byte, internalFormat, format uint32, alignment int32, texelSize int32) (Texture, error) {
+	if levels < 1 || levels > MipLevels(width, height) {
+		return Texture{}, fmt.Errorf("NewTextureLevels(%v): %w", levels, ErrLevelOutOfRange)
+	}
 	t := Texture{
-		width:     width,
-		height:    height,
-		format:    uint32(format),
-		alignment: alignment,
-		texelSize: texelSize,
+		width:          width,
+		height:         height,
+		internalFormat: internalFormat,
+		format:         format,
+		alignment:      alignment,
+		texelSize:      texelSize,
+		levels:         levels,
 	}
+	gl.GenTextures(1, &t.id)
+	t.allocate(data)
+
+	return t, nil
+}
+
+// allocate (re)creates the storage of every mipmap level with the current
+// size, filling the base level with data, which may be nil. Immutable storage
+// can only be allocated once.
+func (t Texture) allocate(data []byte) {
 	var ptr unsafe.Pointer
 	if data != nil {
 		ptr = unsafe.Pointer(&data[0])
 	}
-	gl.GenTextures(1, &t.id)
 	t.Bind()
 	// copy pixels to texture
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, int32(format), width, height, 0, uint32(format), gl.UNSIGNED_BYTE, ptr)
-	gl.GenerateMipmap(gl.TEXTURE_2D)
+	if t.immutable {
+		gl.TexStorage2D(gl.TEXTURE_2D, t.levels, t.internalFormat, t.width, t.height)
+		if data != nil {
+			gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, t.width, t.height, t.format, gl.UNSIGNED_BYTE, ptr)
+		}
+	} else {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, int32(t.internalFormat), t.width, t.height, 0, t.format, gl.UNSIGNED_BYTE, ptr)
+		for level := int32(1); level < t.levels; level++ {
+			gl.TexImage2D(gl.TEXTURE_2D, level, int32(t.internalFormat), mipSize(t.width, level), mipSize(t.height, level), 0, t.format, gl.UNSIGNED_BYTE, nil)
+		}
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, t.levels-1)
+	}
+	if t.levels > 1 && data != nil {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
 	t.Unbind()
-
-	return t, nil
 }
 
 // SetParameter sets the given parameter for the texture.
@@ -107,42 +283,134 @@ const ErrCoordOutOfRange constErr = "coordinates out of range"
 
 // SetPixelArea sets the area of a texture to the given data.
 func (t Texture) SetPixelArea(r Rect, d []byte, genMipmap bool) error {
-	if r.X < 0 || r.Y < 0 || r.X >= t.width || r.Y >= t.height {
-		return fmt.Errorf("SetPixelArea(%v): %w", r, ErrCoordOutOfRange)
+	if err := t.SetLevelPixelArea(0, r, d); err != nil {
+		return err
 	}
-	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
-	gl.TextureSubImage2D(t.id, 0, r.X, r.Y, r.W, r.H, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&d[0]))
 	if genMipmap {
-		t.Bind()
-		gl.GenerateMipmap(gl.TEXTURE_2D)
-		t.Unbind()
+		t.GenerateMipmap()
+	}
+	return nil
+}
+
+// SetLevelPixelArea sets the area of the given mipmap level to the given data.
+// Each row of d must be padded to the alignment of the texture.
+//
+// Possible errors are ErrLevelOutOfRange, *AreaError and *DataSizeError.
+func (t Texture) SetLevelPixelArea(level int32, r Rect, d []byte) error {
+	if level < 0 || level >= t.levels {
+		return fmt.Errorf("SetLevelPixelArea(%v, %v): %w", level, r, ErrLevelOutOfRange)
+	}
+	if err := checkArea("SetLevelPixelArea", r.X, r.Y, 0, r.W, r.H, 1, mipSize(t.width, level), mipSize(t.height, level), 1); err != nil {
+		return err
 	}
+	if err := checkDataSize("SetLevelPixelArea", len(d), r.W, r.H, 1, t.texelSize, t.alignment); err != nil {
+		return err
+	}
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
+	gl.TextureSubImage2D(t.id, level, r.X, r.Y, r.W, r.H, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&d[0]))
 	return nil
 }
 
+// SetLevel replaces all of the data of the given mipmap level.
+//
+// Possible errors are ErrLevelOutOfRange and *DataSizeError.
+func (t Texture) SetLevel(level int32, d []byte) error {
+	if level < 0 || level >= t.levels {
+		return fmt.Errorf("SetLevel(%v): %w", level, ErrLevelOutOfRange)
+	}
+	return t.SetLevelPixelArea(level, Rect{X: 0, Y: 0, W: mipSize(t.width, level), H: mipSize(t.height, level)}, d)
+}
+
 // SetPixel sets the texture at the given point to the given data.
 func (t Texture) SetPixel(p Point, d []byte, genMipmap bool) error {
 	return t.SetPixelArea(Rect{X: p.X, Y: p.Y, W: 1, H: 1}, d, genMipmap)
 }
 
+// GenerateMipmap regenerates all mipmap levels from the base level.
+func (t Texture) GenerateMipmap() {
+	if t.levels < 2 {
+		return
+	}
+	t.Bind()
+	gl.GenerateMipmap(gl.TEXTURE_2D)
+	t.Unbind()
+}
+
+// GenerateMipmapRange regenerates the mipmap levels after baseLevel up to and
+// including maxLevel from level baseLevel.
+func (t Texture) GenerateMipmapRange(baseLevel, maxLevel int32) error {
+	if baseLevel < 0 || maxLevel < baseLevel || maxLevel >= t.levels {
+		return fmt.Errorf("GenerateMipmapRange(%v, %v): %w", baseLevel, maxLevel, ErrLevelOutOfRange)
+	}
+	if baseLevel == maxLevel {
+		return nil
+	}
+	t.Bind()
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, baseLevel)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, maxLevel)
+	gl.GenerateMipmap(gl.TEXTURE_2D)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, t.levels-1)
+	t.Unbind()
+	return nil
+}
+
+// GenerateMipmapFilter regenerates all mipmap levels on the CPU from the base
+// level using the given filter. Each byte of a texel is filtered as a separate
+// channel, so the format must have 8 bits per channel.
+func (t Texture) GenerateMipmapFilter(filter MipFilter) {
+	if t.levels < 2 {
+		return
+	}
+	// read and write tightly packed rows so the chain can be computed directly
+	base := make([]byte, t.width*t.height*t.texelSize)
+	t.Bind()
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.GetTexImage(gl.TEXTURE_2D, 0, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&base[0]))
+	chain := GenerateMipChain(t.width, t.height, t.texelSize, base, t.levels, filter)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	for level := int32(1); level < t.levels; level++ {
+		gl.TexSubImage2D(gl.TEXTURE_2D, level, 0, 0, mipSize(t.width, level), mipSize(t.height, level), t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&chain[level][0]))
+	}
+	t.Unbind()
+}
+
 // GetData returns a byte slice of all the texture data
 func (t Texture) GetData() []byte {
+	// the base level always exists
+	data, _ := t.GetLevelData(0)
+	return data
+}
+
+// GetLevelData returns a byte slice of all the data of the given mipmap level.
+//
+// Possible errors are ErrLevelOutOfRange.
+func (t Texture) GetLevelData(level int32) ([]byte, error) {
+	if level < 0 || level >= t.levels {
+		return nil, fmt.Errorf("GetLevelData(%v): %w", level, ErrLevelOutOfRange)
+	}
 	// TODO do this in batches/stream to avoid memory limitations
-	var data = make([]byte, t.width*t.height*t.texelSize)
+	var data = make([]byte, alignedDataSize(mipSize(t.width, level), mipSize(t.height, level), 1, t.texelSize, t.alignment))
 	t.Bind()
 	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
-	gl.GetTexImage(gl.TEXTURE_2D, 0, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&data[0]))
+	gl.GetTexImage(gl.TEXTURE_2D, level, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&data[0]))
 	t.Unbind()
-	return data
+	return data, nil
 }
 
 // GetSubData returns a portion of the texture data specified by the given Rect.
-func (t Texture) GetSubData(r Rect) []byte {
+// Each row is padded to the alignment of the texture.
+//
+// Possible errors are *AreaError.
+func (t Texture) GetSubData(r Rect) ([]byte, error) {
+	if err := checkArea("GetSubData", r.X, r.Y, 0, r.W, r.H, 1, t.width, t.height, 1); err != nil {
+		return nil, err
+	}
 	// TODO do this in batches/stream to avoid memory limitations
-	var data = make([]byte, r.W*r.H*t.texelSize)
+	var data = make([]byte, alignedDataSize(r.W, r.H, 1, t.texelSize, t.alignment))
 	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
-	gl.GetTextureSubImage(t.id, 0, r.X, r.Y, 0, r.W, r.H, 1, t.format, gl.UNSIGNED_BYTE, r.W*r.H*t.texelSize, unsafe.Pointer(&data[0]))
-	return data
+	gl.GetTextureSubImage(t.id, 0, r.X, r.Y, 0, r.W, r.H, 1, t.format, gl.UNSIGNED_BYTE, int32(len(data)), unsafe.Pointer(&data[0]))
+	return data, nil
 }
 
 // Bind sets this texture as the current texture.
@@ -165,6 +433,39 @@ func (t Texture) GetHeight() int32 {
 	return t.height
 }
 
+// IsSRGB reports whether the texture data is in the sRGB color space.
+func (t Texture) IsSRGB() bool {
+	return t.internalFormat == gl.SRGB8_ALPHA8 || t.internalFormat == gl.SRGB8
+}
+
+// GetAlphaMode returns how the color of the texture data relates to alpha.
+func (t Texture) GetAlphaMode() AlphaMode {
+	return t.alpha
+}
+
+// IsTopRowFirst reports whether the texture data holds the top row of an image
+// first, as for textures loaded from images, rather than the bottom row first,
+// as for rendered textures (see Image).
+func (t Texture) IsTopRowFirst() bool {
+	return t.topRowFirst
+}
+
+// SetTopRowFirst sets whether the texture data holds the top row of an image
+// first, e.g. after uploading decoded image data with NewTexture.
+func (t *Texture) SetTopRowFirst(topRowFirst bool) {
+	t.topRowFirst = topRowFirst
+}
+
+// IsImmutable reports whether the texture has immutable storage.
+func (t Texture) IsImmutable() bool {
+	return t.immutable
+}
+
+// GetLevels returns the number of mipmap levels of the texture.
+func (t Texture) GetLevels() int32 {
+	return t.levels
+}
+
 // Destroy frees external resources.
 func (t Texture) Destroy() {
 	gl.DeleteTextures(1, &t.id)
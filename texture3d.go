@@ -1,7 +1,6 @@
 package gfx
 
 import (
-	"fmt"
 	"unsafe"
 
 	"github.com/go-gl/gl/v2.1/gl"
@@ -9,23 +8,39 @@ import (
 
 // Texture3D wraps an OpenGL texture.
 type Texture3D struct {
-	id        uint32
-	width     int32
-	height    int32
-	depth     int32
-	format    uint32
-	alignment int32
-	texelSize int32
+	id             uint32
+	width          int32
+	height         int32
+	depth          int32
+	internalFormat uint32
+	format         uint32
+	xtype          uint32
+	alignment      int32
+	texelSize      int32
+	spacing        [3]float32
 }
 
+// NewTexture3D creates a Texture3D object that wraps the OpenGL texture
+// functions, with one byte per component.
+// For alignment, see documentation for glPixelStorei.
+// Format specifies the memory format of the data.
 func NewTexture3D(width, height, depth int32, data []byte, format int, alignment int32, texelSize int32) (Texture3D, error) {
+	return newTexture3D(width, height, depth, data, uint32(format), uint32(format), gl.UNSIGNED_BYTE, alignment, texelSize, [3]float32{1, 1, 1})
+}
+
+// newTexture3D creates a Texture3D object with separate internal and data
+// formats, the given component type and voxel spacing.
+func newTexture3D(width, height, depth int32, data []byte, internalFormat, format, xtype uint32, alignment int32, texelSize int32, spacing [3]float32) (Texture3D, error) {
 	t := Texture3D{
-		width:     width,
-		height:    height,
-		depth:     depth,
-		format:    uint32(format),
-		alignment: alignment,
-		texelSize: texelSize,
+		width:          width,
+		height:         height,
+		depth:          depth,
+		internalFormat: internalFormat,
+		format:         format,
+		xtype:          xtype,
+		alignment:      alignment,
+		texelSize:      texelSize,
+		spacing:        spacing,
 	}
 	var ptr unsafe.Pointer
 	if data != nil {
@@ -35,7 +50,7 @@ func NewTexture3D(width, height, depth int32, data []byte, format int, alignment
 	t.Bind()
 	// copy pixels to texture
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
-	gl.TexImage3D(gl.TEXTURE_3D, 0, int32(format), width, height, depth, 0, uint32(format), gl.UNSIGNED_BYTE, ptr)
+	gl.TexImage3D(gl.TEXTURE_3D, 0, int32(internalFormat), width, height, depth, 0, format, xtype, ptr)
 	gl.GenerateMipmap(gl.TEXTURE_3D)
 	t.Unbind()
 
@@ -50,12 +65,18 @@ func (t Texture3D) SetParameter(paramName uint32, param int32) {
 }
 
 // SetPixelArea sets the area of a texture to the given data.
+// Each row of d must be padded to the alignment of the texture.
+//
+// Possible errors are *AreaError and *DataSizeError.
 func (t Texture3D) SetPixelArea(x, y, z, w, h, depth int32, d []byte, genMipmap bool) error {
-	if x < 0 || y < 0 || z < 0 || x >= t.width || y >= t.height || z >= t.depth {
-		return fmt.Errorf("SetPixelArea(%v %v %v %v %v %v): %w", x, y, z, w, h, depth, ErrCoordOutOfRange)
+	if err := checkArea("SetPixelArea", x, y, z, w, h, depth, t.width, t.height, t.depth); err != nil {
+		return err
+	}
+	if err := checkDataSize("SetPixelArea", len(d), w, h, depth, t.texelSize, t.alignment); err != nil {
+		return err
 	}
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
-	gl.TextureSubImage3D(t.id, 0, x, y, z, w, h, depth, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&d[0]))
+	gl.TextureSubImage3D(t.id, 0, x, y, z, w, h, depth, t.format, t.xtype, unsafe.Pointer(&d[0]))
 	if genMipmap {
 		t.Bind()
 		gl.GenerateMipmap(gl.TEXTURE_3D)
@@ -72,10 +93,10 @@ func (t Texture3D) SetPixel(p Point3D, d []byte, genMipmap bool) error {
 // GetData returns a byte slice of all the texture data
 func (t Texture3D) GetData() []byte {
 	// TODO do this in batches/stream to avoid memory limitations
-	var data = make([]byte, t.width*t.height*t.depth*t.texelSize)
+	var data = make([]byte, alignedDataSize(t.width, t.height, t.depth, t.texelSize, t.alignment))
 	t.Bind()
 	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
-	gl.GetTexImage(gl.TEXTURE_3D, 0, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&data[0]))
+	gl.GetTexImage(gl.TEXTURE_3D, 0, t.format, t.xtype, unsafe.Pointer(&data[0]))
 	t.Unbind()
 	return data
 }
@@ -100,6 +121,17 @@ func (t Texture3D) GetHeight() int32 {
 	return t.height
 }
 
+// GetDepth returns the depth of the texture.
+func (t Texture3D) GetDepth() int32 {
+	return t.depth
+}
+
+// GetSpacing returns the size of a voxel along each axis, e.g. in
+// millimeters, as given when loading a volume, or 1 on each axis.
+func (t Texture3D) GetSpacing() [3]float32 {
+	return t.spacing
+}
+
 // Destroy frees external resources.
 func (t Texture3D) Destroy() {
 	gl.DeleteTextures(1, &t.id)
@@ -0,0 +1,87 @@
+package gfx
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+func TestTextureLevelOutOfRange(t *testing.T) {
+	withContext(t, func() {
+		// 4x4 with levels 4x4, 2x2 and 1x1
+		tex, err := NewTextureLevels(4, 4, 3, make([]byte, 4*4*4), gl.RGBA, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tex.Destroy()
+		for _, level := range []int32{-1, 3, 10} {
+			if err := tex.SetLevel(level, make([]byte, 4)); !errors.Is(err, ErrLevelOutOfRange) {
+				t.Errorf("SetLevel(%v) = %v, want ErrLevelOutOfRange", level, err)
+			}
+			if _, err := tex.GetLevelData(level); !errors.Is(err, ErrLevelOutOfRange) {
+				t.Errorf("GetLevelData(%v) = %v, want ErrLevelOutOfRange", level, err)
+			}
+		}
+		if err := tex.SetLevel(2, []byte{1, 2, 3, 4}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := tex.GetLevelData(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "\x01\x02\x03\x04" {
+			t.Errorf("GetLevelData(2) = %v, want [1 2 3 4]", data)
+		}
+	})
+}
+
+func TestTextureImageRowOrder(t *testing.T) {
+	// red top row, blue bottom row
+	img := uniformNRGBA(image.Rect(0, 0, 2, 2), testRed)
+	img.SetNRGBA(0, 1, testBlue)
+	img.SetNRGBA(1, 1, testBlue)
+	withContext(t, func() {
+		file := filepath.Join(t.TempDir(), "in.png")
+		if err := writePNG(file, img); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := NewTextureFromFileLinear(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer loaded.Destroy()
+		if !loaded.IsTopRowFirst() {
+			t.Error("loaded texture is not top row first")
+		}
+		out := filepath.Join(t.TempDir(), "out.png")
+		if err := loaded.WritePNG(out); err != nil {
+			t.Fatal(err)
+		}
+		written, err := loadImage(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := color.NRGBAModel.Convert(written.At(0, 0)); got != testRed {
+			t.Errorf("loaded: top left = %v, want %v", got, testRed)
+		}
+
+		// the same data uploaded directly is taken as rendered, bottom row
+		// first
+		raw, err := NewTexture(2, 2, img.Pix, gl.RGBA, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer raw.Destroy()
+		if got := color.NRGBAModel.Convert(raw.Image().At(0, 0)); got != testBlue {
+			t.Errorf("raw: top left = %v, want %v", got, testBlue)
+		}
+		raw.SetTopRowFirst(true)
+		if got := color.NRGBAModel.Convert(raw.Image().At(0, 0)); got != testRed {
+			t.Errorf("raw top row first: top left = %v, want %v", got, testRed)
+		}
+	})
+}
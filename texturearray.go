@@ -0,0 +1,280 @@
+package gfx
+
+import (
+	"fmt"
+	"image"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// TextureArray wraps an OpenGL 2D array texture.
+type TextureArray struct {
+	id             uint32
+	width          int32
+	height         int32
+	layers         int32
+	internalFormat uint32
+	format         uint32
+	alignment      int32
+	texelSize      int32
+	levels         int32
+	immutable      bool
+}
+
+// NewTextureArray creates a TextureArray object that wraps the OpenGL texture
+// functions. Data holds the layers one after another, and may be nil.
+// For alignment, see documentation for glPixelStorei.
+// Format specifies the memory format of the data.
+//
+// Possible errors are *DataSizeError.
+func NewTextureArray(width, height, layers int32, data []byte, format int, alignment int32, texelSize int32) (TextureArray, error) {
+	if data != nil {
+		if err := checkDataSize("NewTextureArray", len(data), width, height, layers, texelSize, alignment); err != nil {
+			return TextureArray{}, err
+		}
+	}
+	t := TextureArray{
+		width:          width,
+		height:         height,
+		layers:         layers,
+		internalFormat: uint32(format),
+		format:         uint32(format),
+		alignment:      alignment,
+		texelSize:      texelSize,
+		levels:         MipLevels(width, height),
+	}
+	t.allocate(data)
+	return t, nil
+}
+
+// NewTextureArrayStorage creates a TextureArray object with immutable storage
+// for the given number of mipmap levels (see NewTextureStorage).
+//
+// Possible errors are ErrLevelOutOfRange and *DataSizeError.
+func NewTextureArrayStorage(width, height, layers, levels int32, internalFormat uint32, data []byte, format int, alignment int32, texelSize int32) (TextureArray, error) {
+	if levels < 1 || levels > MipLevels(width, height) {
+		return TextureArray{}, fmt.Errorf("NewTextureArrayStorage(%v): %w", levels, ErrLevelOutOfRange)
+	}
+	if data != nil {
+		if err := checkDataSize("NewTextureArrayStorage", len(data), width, height, layers, texelSize, alignment); err != nil {
+			return TextureArray{}, err
+		}
+	}
+	t := TextureArray{
+		width:          width,
+		height:         height,
+		layers:         layers,
+		internalFormat: internalFormat,
+		format:         uint32(format),
+		alignment:      alignment,
+		texelSize:      texelSize,
+		levels:         levels,
+		immutable:      true,
+	}
+	t.allocate(data)
+	return t, nil
+}
+
+// allocate creates the texture and its storage, filling the base level with
+// data, which may be nil.
+func (t *TextureArray) allocate(data []byte) {
+	var ptr unsafe.Pointer
+	if data != nil {
+		ptr = unsafe.Pointer(&data[0])
+	}
+	gl.GenTextures(1, &t.id)
+	t.Bind()
+	// copy pixels to texture
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
+	if t.immutable {
+		gl.TexStorage3D(gl.TEXTURE_2D_ARRAY, t.levels, t.internalFormat, t.width, t.height, t.layers)
+		if data != nil {
+			gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, 0, t.width, t.height, t.layers, t.format, gl.UNSIGNED_BYTE, ptr)
+		}
+	} else {
+		gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, int32(t.internalFormat), t.width, t.height, t.layers, 0, t.format, gl.UNSIGNED_BYTE, ptr)
+	}
+	if t.levels > 1 && data != nil {
+		gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+	}
+	t.Unbind()
+}
+
+// ErrLayerSize indicates that the layers of a texture array differ in size.
+const ErrLayerSize constErr = "layer dimensions do not match"
+
+// NewTextureArrayFromImages creates a new RGBA TextureArray with one layer per
+// image. All images must have the same dimensions.
+func NewTextureArrayFromImages(imgs []image.Image) (TextureArray, error) {
+	if len(imgs) == 0 {
+		return TextureArray{}, ErrEmptyData
+	}
+	width := imgs[0].Bounds().Dx()
+	height := imgs[0].Bounds().Dy()
+	data := make([]byte, 0, width*height*4*len(imgs))
+	for i, img := range imgs {
+		if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+			return TextureArray{}, fmt.Errorf("layer %v is %vx%v, want %vx%v: %w",
+				i, img.Bounds().Dx(), img.Bounds().Dy(), width, height, ErrLayerSize)
+		}
+		data = append(data, imageToNRGBA(img)...)
+	}
+	t, err := NewTextureArray(int32(width), int32(height), int32(len(imgs)), data, gl.RGBA, 4, 4)
+	t.SetParameter(gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_NEAREST)
+	t.SetParameter(gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	return t, err
+}
+
+// NewTextureArrayFromFiles creates a new RGBA TextureArray, loading one layer
+// from each of fileNames in order.
+//
+// To provide support for loading different image types, blank import the
+// respective image/* packages.
+func NewTextureArrayFromFiles(fileNames []string) (TextureArray, error) {
+	imgs := make([]image.Image, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		img, err := loadImage(fileName)
+		if err != nil {
+			return TextureArray{}, err
+		}
+		imgs = append(imgs, img)
+	}
+	return NewTextureArrayFromImages(imgs)
+}
+
+// imageExtensions holds the file extensions NewTextureArrayFromDir loads.
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".bmp":  true,
+	".tif":  true,
+	".tiff": true,
+	".webp": true,
+}
+
+// NewTextureArrayFromDir creates a new RGBA TextureArray, loading one layer
+// from each image file in dirName, ordered by file name. Files without an
+// image extension (.png, .jpg, .jpeg, .gif, .bmp, .tif, .tiff or .webp) are
+// skipped.
+func NewTextureArrayFromDir(dirName string) (TextureArray, error) {
+	infos, err := ioutil.ReadDir(dirName)
+	if err != nil {
+		return TextureArray{}, err
+	}
+	var fileNames []string
+	for _, info := range infos {
+		if info.IsDir() || !imageExtensions[strings.ToLower(filepath.Ext(info.Name()))] {
+			continue
+		}
+		fileNames = append(fileNames, filepath.Join(dirName, info.Name()))
+	}
+	return NewTextureArrayFromFiles(fileNames)
+}
+
+// SetParameter sets the given parameter for the texture.
+func (t TextureArray) SetParameter(paramName uint32, param int32) {
+	t.Bind()
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, paramName, param)
+	t.Unbind()
+}
+
+// SetPixelArea sets the area of the given layer to the given data.
+// Each row of d must be padded to the alignment of the texture.
+//
+// Possible errors are *AreaError and *DataSizeError.
+func (t TextureArray) SetPixelArea(layer int32, r Rect, d []byte, genMipmap bool) error {
+	if err := checkArea("SetPixelArea", r.X, r.Y, layer, r.W, r.H, 1, t.width, t.height, t.layers); err != nil {
+		return err
+	}
+	if err := checkDataSize("SetPixelArea", len(d), r.W, r.H, 1, t.texelSize, t.alignment); err != nil {
+		return err
+	}
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, t.alignment)
+	gl.TextureSubImage3D(t.id, 0, r.X, r.Y, layer, r.W, r.H, 1, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&d[0]))
+	if genMipmap {
+		t.GenerateMipmap()
+	}
+	return nil
+}
+
+// SetLayer replaces all of the data of the given layer.
+func (t TextureArray) SetLayer(layer int32, d []byte, genMipmap bool) error {
+	return t.SetPixelArea(layer, Rect{X: 0, Y: 0, W: t.width, H: t.height}, d, genMipmap)
+}
+
+// GenerateMipmap regenerates the mipmaps of every layer.
+func (t TextureArray) GenerateMipmap() {
+	t.Bind()
+	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+	t.Unbind()
+}
+
+// GetData returns a byte slice of all the texture data, layer after layer.
+func (t TextureArray) GetData() []byte {
+	// TODO do this in batches/stream to avoid memory limitations
+	var data = make([]byte, alignedDataSize(t.width, t.height, t.layers, t.texelSize, t.alignment))
+	t.Bind()
+	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
+	gl.GetTexImage(gl.TEXTURE_2D_ARRAY, 0, t.format, gl.UNSIGNED_BYTE, unsafe.Pointer(&data[0]))
+	t.Unbind()
+	return data
+}
+
+// GetLayerData returns a byte slice of the texture data of the given layer.
+//
+// Possible errors are ErrCoordOutOfRange for an invalid layer.
+func (t TextureArray) GetLayerData(layer int32) ([]byte, error) {
+	if layer < 0 || layer >= t.layers {
+		return nil, fmt.Errorf("GetLayerData(%v): %w", layer, ErrCoordOutOfRange)
+	}
+	var data = make([]byte, alignedDataSize(t.width, t.height, 1, t.texelSize, t.alignment))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, t.alignment)
+	gl.GetTextureSubImage(t.id, 0, 0, 0, layer, t.width, t.height, 1, t.format, gl.UNSIGNED_BYTE, int32(len(data)), unsafe.Pointer(&data[0]))
+	return data, nil
+}
+
+// Bind sets this texture as the current texture.
+func (t TextureArray) Bind() {
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, t.id)
+}
+
+// Unbind unsets the current texture.
+func (t TextureArray) Unbind() {
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, 0)
+}
+
+// GetWidth returns the width of the texture.
+func (t TextureArray) GetWidth() int32 {
+	return t.width
+}
+
+// GetHeight returns the height of the texture.
+func (t TextureArray) GetHeight() int32 {
+	return t.height
+}
+
+// GetLayers returns the number of layers in the texture.
+func (t TextureArray) GetLayers() int32 {
+	return t.layers
+}
+
+// GetLevels returns the number of mipmap levels of the texture.
+func (t TextureArray) GetLevels() int32 {
+	return t.levels
+}
+
+// IsImmutable reports whether the texture has immutable storage.
+func (t TextureArray) IsImmutable() bool {
+	return t.immutable
+}
+
+// Destroy frees external resources.
+func (t TextureArray) Destroy() {
+	gl.DeleteTextures(1, &t.id)
+}
@@ -0,0 +1,34 @@
+package gfx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+func TestNewTextureArrayDataSize(t *testing.T) {
+	// 3x2 with rows padded to 4 bytes, 2 layers
+	for _, size := range []int{0, 14, 17} {
+		var sizeErr *DataSizeError
+		if _, err := NewTextureArray(3, 2, 2, make([]byte, size), gl.RED, 4, 1); !errors.As(err, &sizeErr) {
+			t.Errorf("NewTextureArray with %v bytes: err = %v, want *DataSizeError", size, err)
+		}
+		if _, err := NewTextureArrayStorage(3, 2, 2, 1, gl.R8, make([]byte, size), gl.RED, 4, 1); !errors.As(err, &sizeErr) {
+			t.Errorf("NewTextureArrayStorage with %v bytes: err = %v, want *DataSizeError", size, err)
+		}
+	}
+}
+
+func TestNewTextureArrayNoData(t *testing.T) {
+	withContext(t, func() {
+		tex, err := NewTextureArray(4, 4, 2, nil, gl.RGBA, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tex.Destroy()
+		if code := gl.GetError(); code != gl.NO_ERROR {
+			t.Errorf("GL error 0x%X", code)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package gfx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// glVersion caches the OpenGL version, which is parsed on first use.
+var glVersion struct {
+	major, minor int
+	parsed       bool
+}
+
+// glVersionAtLeast reports whether the current context's OpenGL version is at
+// least major.minor.
+func glVersionAtLeast(major, minor int) bool {
+	if !glVersion.parsed {
+		version := gl.GetString(gl.VERSION)
+		if version == nil {
+			return false
+		}
+		v := &glVersion
+		if _, err := fmt.Sscanf(gl.GoStr(version), "%d.%d", &v.major, &v.minor); err != nil {
+			return false
+		}
+		v.parsed = true
+	}
+	return glVersion.major > major || (glVersion.major == major && glVersion.minor >= minor)
+}
+
+// glExtensions caches the extensions of the context, which are read on first
+// use.
+var glExtensions map[string]bool
+
+// glExtension reports whether the current context supports the named
+// extension, e.g. "GL_EXT_texture_filter_anisotropic".
+func glExtension(name string) bool {
+	if glExtensions == nil {
+		list := gl.GetString(gl.EXTENSIONS)
+		if list == nil {
+			return false
+		}
+		glExtensions = make(map[string]bool)
+		for _, ext := range strings.Fields(gl.GoStr(list)) {
+			glExtensions[ext] = true
+		}
+	}
+	return glExtensions[name]
+}
+
+// rectInside reports whether r lies within a width by height area.
+func rectInside(r Rect, width, height int32) bool {
+	return r.X >= 0 && r.Y >= 0 && r.W >= 0 && r.H >= 0 &&
+		r.X+r.W <= width && r.Y+r.H <= height
+}
+
+// CopyTexture copies the area r of the base level of src to the base level
+// of dst at dstPos, without going through the CPU. It uses glCopyImageSubData
+// on OpenGL 4.3 and up, and a frame buffer blit otherwise.
+func CopyTexture(dst Texture, dstPos Point, src Texture, r Rect) error {
+	dstRect := Rect{X: dstPos.X, Y: dstPos.Y, W: r.W, H: r.H}
+	if !rectInside(r, src.width, src.height) || !rectInside(dstRect, dst.width, dst.height) {
+		return fmt.Errorf("CopyTexture(%v, %v): %w", dstPos, r, ErrCoordOutOfRange)
+	}
+	if glVersionAtLeast(4, 3) {
+		gl.CopyImageSubData(src.id, gl.TEXTURE_2D, 0, r.X, r.Y, 0, dst.id, gl.TEXTURE_2D, 0, dstPos.X, dstPos.Y, 0, r.W, r.H, 1)
+		return nil
+	}
+	return blitTexture(dst, dstRect, src, r, gl.NEAREST)
+}
+
+// BlitTexture copies the area srcRect of the base level of src to the area
+// dstRect of the base level of dst, scaling it with the given filter
+// (FilterNearest or FilterLinear). Both textures must be color renderable.
+func BlitTexture(dst Texture, dstRect Rect, src Texture, srcRect Rect, filter Filter) error {
+	if !rectInside(srcRect, src.width, src.height) || !rectInside(dstRect, dst.width, dst.height) {
+		return fmt.Errorf("BlitTexture(%v, %v): %w", dstRect, srcRect, ErrCoordOutOfRange)
+	}
+	return blitTexture(dst, dstRect, src, srcRect, uint32(filter))
+}
+
+// blitTexture copies between textures by attaching them to temporary read and
+// draw frame buffers. The previous frame buffer bindings are restored.
+func blitTexture(dst Texture, dstRect Rect, src Texture, srcRect Rect, filter uint32) error {
+	var prevRead, prevDraw int32
+	gl.GetIntegerv(gl.READ_FRAMEBUFFER_BINDING, &prevRead)
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &prevDraw)
+	var fbos [2]uint32
+	gl.GenFramebuffers(2, &fbos[0])
+	defer gl.DeleteFramebuffers(2, &fbos[0])
+	defer func() {
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(prevRead))
+		gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(prevDraw))
+	}()
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, fbos[0])
+	gl.FramebufferTexture2D(gl.READ_FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, src.id, 0)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, fbos[1])
+	gl.FramebufferTexture2D(gl.DRAW_FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, dst.id, 0)
+	if gl.CheckFramebufferStatus(gl.READ_FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE ||
+		gl.CheckFramebufferStatus(gl.DRAW_FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
+		return ErrFrameBuffer
+	}
+	gl.BlitFramebuffer(
+		srcRect.X, srcRect.Y, srcRect.X+srcRect.W, srcRect.Y+srcRect.H,
+		dstRect.X, dstRect.Y, dstRect.X+dstRect.W, dstRect.Y+dstRect.H,
+		gl.COLOR_BUFFER_BIT, filter)
+	return nil
+}
+
+// Resize reallocates the storage of the texture with a new size under the
+// same ID. If preserve is true, the overlapping area of the old contents is
+// kept in place and the mipmaps are regenerated; otherwise the contents are
+// undefined. Use BlitTexture to scale contents instead.
+//
+// Possible errors are ErrImmutable for textures with immutable storage.
+func (t *Texture) Resize(width, height int32, preserve bool) error {
+	if t.immutable {
+		return fmt.Errorf("Resize(%v, %v): %w", width, height, ErrImmutable)
+	}
+	if width < 1 || height < 1 {
+		return fmt.Errorf("Resize(%v, %v): %w", width, height, ErrCoordOutOfRange)
+	}
+	if !preserve {
+		t.setSize(width, height)
+		t.allocate(nil)
+		return nil
+	}
+
+	old, err := newTexture(t.width, t.height, 1, nil, t.internalFormat, t.format, t.alignment, t.texelSize)
+	if err != nil {
+		return err
+	}
+	defer old.Destroy()
+	if err := CopyTexture(old, Point{}, *t, Rect{X: 0, Y: 0, W: t.width, H: t.height}); err != nil {
+		return err
+	}
+	t.setSize(width, height)
+	t.allocate(nil)
+	keep := Rect{X: 0, Y: 0, W: minInt32(old.width, width), H: minInt32(old.height, height)}
+	if err := CopyTexture(*t, Point{}, old, keep); err != nil {
+		return err
+	}
+	t.GenerateMipmap()
+	return nil
+}
+
+// setSize changes the recorded size of the texture, keeping a full mipmap
+// chain full and clamping the number of levels to the new size.
+func (t *Texture) setSize(width, height int32) {
+	fullChain := t.levels == MipLevels(t.width, t.height)
+	t.width = width
+	t.height = height
+	if fullChain || t.levels > MipLevels(width, height) {
+		t.levels = MipLevels(width, height)
+	}
+}
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
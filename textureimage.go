@@ -0,0 +1,176 @@
+package gfx
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Image returns the texture data as an image.Image. Red textures become
+// *image.Gray, alpha textures *image.Alpha, linear premultiplied alpha
+// textures *image.RGBA and all others *image.NRGBA. Premultiplied sRGB
+// textures are unpremultiplied (see PremultiplySRGB).
+//
+// The top row of the image is the top row of the texture data for textures
+// loaded from images, and the last row otherwise, since OpenGL renders the
+// bottom row first, so that both appear upright (see IsTopRowFirst).
+func (t Texture) Image() image.Image {
+	return t.image(!t.topRowFirst)
+}
+
+// image returns the texture data as an image.Image, optionally flipping rows.
+func (t Texture) image(flip bool) image.Image {
+	rect := image.Rect(0, 0, int(t.width), int(t.height))
+	var img image.Image
+	var pix []byte
+	var stride int
+	switch t.format {
+	case gl.RED, gl.LUMINANCE:
+		gray := image.NewGray(rect)
+		t.readPixels(gl.RED, gl.UNSIGNED_BYTE, unsafe.Pointer(&gray.Pix[0]))
+		img, pix, stride = gray, gray.Pix, gray.Stride
+	case gl.ALPHA:
+		alpha := image.NewAlpha(rect)
+		t.readPixels(gl.ALPHA, gl.UNSIGNED_BYTE, unsafe.Pointer(&alpha.Pix[0]))
+		img, pix, stride = alpha, alpha.Pix, alpha.Stride
+	default:
+		if t.alpha == AlphaPremultiplied && !t.IsSRGB() {
+			rgba := image.NewRGBA(rect)
+			t.readPixels(gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&rgba.Pix[0]))
+			img, pix, stride = rgba, rgba.Pix, rgba.Stride
+			break
+		}
+		nrgba := image.NewNRGBA(rect)
+		t.readPixels(gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&nrgba.Pix[0]))
+		if t.alpha == AlphaPremultiplied {
+			// premultiplied in linear space, which image.RGBA cannot express
+			UnpremultiplySRGB(nrgba.Pix)
+		}
+		img, pix, stride = nrgba, nrgba.Pix, nrgba.Stride
+	}
+	if flip {
+		flipRows(pix, stride)
+	}
+	return img
+}
+
+// readPixels reads the base level into ptr, converted to the given format and
+// type, with tightly packed rows.
+func (t Texture) readPixels(format, xtype uint32, ptr unsafe.Pointer) {
+	t.Bind()
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.GetTexImage(gl.TEXTURE_2D, 0, format, xtype, ptr)
+	t.Unbind()
+}
+
+// flipRows reverses the order of the rows of pix in place.
+func flipRows(pix []byte, stride int) {
+	tmp := make([]byte, stride)
+	rows := len(pix) / stride
+	for top, bottom := 0, rows-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := pix[top*stride : (top+1)*stride]
+		b := pix[bottom*stride : (bottom+1)*stride]
+		copy(tmp, a)
+		copy(a, b)
+		copy(b, tmp)
+	}
+}
+
+// GetFloatData returns the RGB data of the texture as float32 values, in the
+// row order of the texture (see IsTopRowFirst).
+func (t Texture) GetFloatData() []float32 {
+	data := make([]float32, t.width*t.height*3)
+	t.readPixels(gl.RGB, gl.FLOAT, unsafe.Pointer(&data[0]))
+	return data
+}
+
+// WritePNG saves the texture as a PNG image to fileName (see Image).
+func (t Texture) WritePNG(fileName string) error {
+	return writePNG(fileName, t.Image())
+}
+
+// WriteHDR saves the RGB data of the texture as a Radiance HDR image to
+// fileName, keeping the full range of float textures.
+func (t Texture) WriteHDR(fileName string) error {
+	data := t.GetFloatData()
+	flipped := data
+	if !t.topRowFirst {
+		// flip rows so the top row comes first
+		rowLen := int(t.width) * 3
+		flipped = make([]float32, 0, len(data))
+		for row := int(t.height) - 1; row >= 0; row-- {
+			flipped = append(flipped, data[row*rowLen:(row+1)*rowLen]...)
+		}
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err = EncodeHDR(file, int(t.width), int(t.height), flipped); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Image returns the contents of the frame buffer's texture (see
+// Texture.Image).
+func (fb FrameBuffer) Image() image.Image {
+	return fb.tex.Image()
+}
+
+// writePNG saves img as a PNG image to fileName.
+func writePNG(fileName string, img image.Image) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// EncodeHDR writes RGB float data, top row first, to w as an uncompressed
+// Radiance HDR (RGBE) image.
+func EncodeHDR(w io.Writer, width, height int, rgb []float32) error {
+	if len(rgb) != width*height*3 {
+		return fmt.Errorf("EncodeHDR(%v, %v) with %v floats: %w", width, height, len(rgb), ErrOutOfBounds)
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", height, width); err != nil {
+		return err
+	}
+	for i := 0; i < len(rgb); i += 3 {
+		e := toRGBE(rgb[i], rgb[i+1], rgb[i+2])
+		if _, err := bw.Write(e[:]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// toRGBE encodes a color with a shared exponent.
+func toRGBE(r, g, b float32) [4]byte {
+	v := math.Max(float64(r), math.Max(float64(g), float64(b)))
+	if v < 1e-32 {
+		return [4]byte{}
+	}
+	frac, exp := math.Frexp(v)
+	scale := frac * 256 / v
+	return [4]byte{
+		byte(math.Max(0, float64(r)*scale)),
+		byte(math.Max(0, float64(g)*scale)),
+		byte(math.Max(0, float64(b)*scale)),
+		byte(exp + 128),
+	}
+}
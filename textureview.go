@@ -0,0 +1,150 @@
+package gfx
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// TextureView wraps an OpenGL texture view, a texture sharing the storage of
+// another texture with immutable storage. A view can reinterpret the data in
+// a compatible internal format, for example gl.SRGB8_ALPHA8 for gl.RGBA8, and
+// cover a subset of the mipmap levels and layers of the original.
+//
+// Texture views require OpenGL 4.3.
+type TextureView struct {
+	id             uint32
+	target         uint32
+	width          int32
+	height         int32
+	internalFormat uint32
+	levels         int32
+}
+
+// ErrMutable indicates that a texture does not have immutable storage.
+const ErrMutable constErr = "texture storage is not immutable"
+
+// NewTextureView creates a 2D view of the mipmap levels minLevel through
+// minLevel+numLevels-1 of src, in the given internal format.
+//
+// Possible errors are ErrMutable if src does not have immutable storage and
+// ErrLevelOutOfRange.
+func NewTextureView(src Texture, internalFormat uint32, minLevel, numLevels int32) (TextureView, error) {
+	if !src.immutable {
+		return TextureView{}, fmt.Errorf("NewTextureView: %w", ErrMutable)
+	}
+	return newTextureView("NewTextureView", src.id, gl.TEXTURE_2D, src.width, src.height, src.levels,
+		internalFormat, minLevel, numLevels, 0, 1)
+}
+
+// NewTextureArrayLayerView creates a 2D view of a single layer of src (see
+// NewTextureView).
+//
+// Possible errors are ErrMutable, ErrLevelOutOfRange and ErrCoordOutOfRange
+// for an invalid layer.
+func NewTextureArrayLayerView(src TextureArray, layer int32, internalFormat uint32, minLevel, numLevels int32) (TextureView, error) {
+	if !src.immutable {
+		return TextureView{}, fmt.Errorf("NewTextureArrayLayerView: %w", ErrMutable)
+	}
+	if layer < 0 || layer >= src.layers {
+		return TextureView{}, fmt.Errorf("NewTextureArrayLayerView(%v): %w", layer, ErrCoordOutOfRange)
+	}
+	return newTextureView("NewTextureArrayLayerView", src.id, gl.TEXTURE_2D, src.width, src.height, src.levels,
+		internalFormat, minLevel, numLevels, layer, 1)
+}
+
+// NewCubeMapFaceView creates a 2D view of a face of the given layer of src
+// (see NewTextureView).
+//
+// Possible errors are ErrMutable, ErrLevelOutOfRange and ErrCoordOutOfRange
+// for an invalid layer or face.
+func NewCubeMapFaceView(src CubeMap, layer int32, face CubeFace, internalFormat uint32, minLevel, numLevels int32) (TextureView, error) {
+	if !src.immutable {
+		return TextureView{}, fmt.Errorf("NewCubeMapFaceView: %w", ErrMutable)
+	}
+	if layer < 0 || layer >= src.layers || face < CubeFacePosX || face > CubeFaceNegZ {
+		return TextureView{}, fmt.Errorf("NewCubeMapFaceView(%v, %v): %w", layer, face, ErrCoordOutOfRange)
+	}
+	return newTextureView("NewCubeMapFaceView", src.id, gl.TEXTURE_2D, src.width, src.width, src.levels,
+		internalFormat, minLevel, numLevels, layer*6+int32(face), 1)
+}
+
+// NewCubeMapLayerView creates a cube map view (samplerCube) of the given layer
+// of src, which is useful to sample a single layer of a cube map array (see
+// NewTextureView).
+//
+// Possible errors are ErrMutable, ErrLevelOutOfRange and ErrCoordOutOfRange
+// for an invalid layer.
+func NewCubeMapLayerView(src CubeMap, layer int32, internalFormat uint32, minLevel, numLevels int32) (TextureView, error) {
+	if !src.immutable {
+		return TextureView{}, fmt.Errorf("NewCubeMapLayerView: %w", ErrMutable)
+	}
+	if layer < 0 || layer >= src.layers {
+		return TextureView{}, fmt.Errorf("NewCubeMapLayerView(%v): %w", layer, ErrCoordOutOfRange)
+	}
+	return newTextureView("NewCubeMapLayerView", src.id, gl.TEXTURE_CUBE_MAP, src.width, src.width, src.levels,
+		internalFormat, minLevel, numLevels, layer*6, 6)
+}
+
+// newTextureView creates a view of the texture orig, whose base level is
+// width by height with srcLevels levels.
+func newTextureView(op string, orig, target uint32, width, height, srcLevels int32, internalFormat uint32, minLevel, numLevels, minLayer, numLayers int32) (TextureView, error) {
+	if minLevel < 0 || numLevels < 1 || minLevel+numLevels > srcLevels {
+		return TextureView{}, fmt.Errorf("%v(%v, %v): %w", op, minLevel, numLevels, ErrLevelOutOfRange)
+	}
+	v := TextureView{
+		target:         target,
+		width:          mipSize(width, minLevel),
+		height:         mipSize(height, minLevel),
+		internalFormat: internalFormat,
+		levels:         numLevels,
+	}
+	// a view must be created from a new name that was never bound
+	gl.GenTextures(1, &v.id)
+	gl.TextureView(v.id, target, orig, internalFormat, uint32(minLevel), uint32(numLevels), uint32(minLayer), uint32(numLayers))
+	return v, nil
+}
+
+// SetParameter sets the given parameter for the view.
+func (v TextureView) SetParameter(paramName uint32, param int32) {
+	v.Bind()
+	gl.TexParameteri(v.target, paramName, param)
+	v.Unbind()
+}
+
+// Bind sets this view as the current texture.
+func (v TextureView) Bind() {
+	gl.BindTexture(v.target, v.id)
+}
+
+// Unbind unsets the current texture.
+func (v TextureView) Unbind() {
+	gl.BindTexture(v.target, 0)
+}
+
+// GetWidth returns the width of the base level of the view.
+func (v TextureView) GetWidth() int32 {
+	return v.width
+}
+
+// GetHeight returns the height of the base level of the view.
+func (v TextureView) GetHeight() int32 {
+	return v.height
+}
+
+// GetLevels returns the number of mipmap levels of the view.
+func (v TextureView) GetLevels() int32 {
+	return v.levels
+}
+
+// GetInternalFormat returns the internal format the view interprets the data
+// in.
+func (v TextureView) GetInternalFormat() uint32 {
+	return v.internalFormat
+}
+
+// Destroy frees the view. The storage is freed once the original texture
+// and all views of it are destroyed.
+func (v TextureView) Destroy() {
+	gl.DeleteTextures(1, &v.id)
+}
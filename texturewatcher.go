@@ -0,0 +1,133 @@
+package gfx
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type watchedTexture struct {
+	tex      *Texture
+	fileName string
+	opts     TextureOptions
+	modTime  time.Time
+}
+
+// TextureWatcher reloads textures loaded from files when the files change on
+// disk. Reloaded textures keep their OpenGL texture ID, so anything referring
+// to them, such as frame buffers or bound texture units, sees the new data.
+//
+// If the size of an image changes, the texture is reallocated with the new
+// size under the same ID. Only the *Texture returned by Load is updated, so
+// Texture values copied from it earlier keep the old size and must not be
+// used to read or write the texture after such a reload.
+type TextureWatcher struct {
+	textures []*watchedTexture
+	interval time.Duration
+	lastPoll time.Time
+}
+
+// NewTextureWatcher creates a TextureWatcher that checks files at most every
+// interval.
+func NewTextureWatcher(interval time.Duration) *TextureWatcher {
+	return &TextureWatcher{interval: interval}
+}
+
+// Load creates a new Texture like NewTextureFromFileOptions and watches
+// fileName for changes. The returned Texture is updated in place on reload,
+// including its size.
+func (w *TextureWatcher) Load(fileName string, opts TextureOptions) (*Texture, error) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return nil, err
+	}
+	tex, err := NewTextureFromFileOptions(fileName, opts)
+	if err != nil {
+		return nil, err
+	}
+	w.textures = append(w.textures, &watchedTexture{
+		tex:      &tex,
+		fileName: fileName,
+		opts:     opts,
+		modTime:  info.ModTime(),
+	})
+	return &tex, nil
+}
+
+// Unwatch stops watching the file of the given texture. It does not destroy
+// the texture.
+func (w *TextureWatcher) Unwatch(tex *Texture) {
+	for i, wt := range w.textures {
+		if wt.tex == tex {
+			w.textures = append(w.textures[:i], w.textures[i+1:]...)
+			return
+		}
+	}
+}
+
+// Poll reloads every watched texture whose file modification time changed,
+// and returns the names of the reloaded files. It must be called from the
+// thread owning the OpenGL context, e.g. once per frame; calls within the
+// interval of the previous check do nothing.
+//
+// A file that fails to load, for example because it is still being written,
+// is retried on the next check, and the first such error is returned.
+func (w *TextureWatcher) Poll() ([]string, error) {
+	now := time.Now()
+	if now.Sub(w.lastPoll) < w.interval {
+		return nil, nil
+	}
+	w.lastPoll = now
+
+	var reloaded []string
+	var firstErr error
+	for _, wt := range w.textures {
+		info, err := os.Stat(wt.fileName)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if info.ModTime().Equal(wt.modTime) {
+			continue
+		}
+		img, err := loadImage(wt.fileName)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		wt.modTime = info.ModTime()
+		err = wt.tex.reload(imageToNRGBA(img), int32(img.Bounds().Dx()), int32(img.Bounds().Dy()), wt.opts)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%v: %w", wt.fileName, err)
+			}
+			continue
+		}
+		reloaded = append(reloaded, wt.fileName)
+	}
+	return reloaded, firstErr
+}
+
+// reload replaces the contents of the texture with RGBA data of the given
+// size, reallocating its storage under the same ID if the size changed.
+//
+// Possible errors are ErrImmutable if the size changed and the texture has
+// immutable storage.
+func (t *Texture) reload(data []byte, width, height int32, opts TextureOptions) error {
+	if opts.Alpha == AlphaPremultiplied {
+		premultiply(data, t.IsSRGB())
+	}
+	if width == t.width && height == t.height {
+		return t.SetPixelArea(Rect{X: 0, Y: 0, W: width, H: height}, data, true)
+	}
+	if t.immutable {
+		return fmt.Errorf("reload(%v, %v): %w", width, height, ErrImmutable)
+	}
+	t.setSize(width, height)
+	t.allocate(data)
+	return nil
+}
@@ -0,0 +1,86 @@
+package gfx
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// UniformBuffer holds the data of a std140 uniform block, encoded from a Go
+// struct (see EncodeStd140), at a uniform buffer binding point.
+type UniformBuffer struct {
+	bo      *BufferObject
+	binding uint32
+}
+
+// NewUniformBuffer creates a UniformBuffer holding v and binds it to the given
+// binding point.
+//
+// Possible errors are ErrBlockType.
+func NewUniformBuffer(v interface{}, binding uint32) (*UniformBuffer, error) {
+	data, err := EncodeStd140(v)
+	if err != nil {
+		return nil, err
+	}
+	u := &UniformBuffer{
+		bo:      NewBufferObject(),
+		binding: binding,
+	}
+	// a new buffer is mutable, so this cannot fail
+	_ = u.bo.BufferData(gl.UNIFORM_BUFFER, uint32(len(data)), slicePtr(data), gl.DYNAMIC_DRAW)
+	u.Bind()
+	return u, nil
+}
+
+// Upload replaces the data of the buffer with v, which is usually of the same
+// type the buffer was created with.
+//
+// Possible errors are ErrBlockType and ErrBufferImmutable if the size of the
+// data changed and the buffer has immutable storage.
+func (u *UniformBuffer) Upload(v interface{}) error {
+	data, err := EncodeStd140(v)
+	if err != nil {
+		return err
+	}
+	if uint32(len(data)) != u.bo.GetSizeBytes() {
+		return u.bo.BufferData(gl.UNIFORM_BUFFER, uint32(len(data)), slicePtr(data), gl.DYNAMIC_DRAW)
+	}
+	return u.bo.BufferSubData(gl.UNIFORM_BUFFER, 0, uint32(len(data)), slicePtr(data))
+}
+
+// BindToBlock assigns the uniform block with the given name in p to the
+// binding point of the buffer.
+//
+// Possible errors are ErrInvalidName and ErrDataSize if the buffer is smaller
+// than the block.
+func (u *UniformBuffer) BindToBlock(p Program, blockName string) error {
+	size, err := p.GetUniformBlockSize(blockName)
+	if err != nil {
+		return err
+	}
+	if size > u.bo.GetSizeBytes() {
+		return fmt.Errorf("uniform block \"%v\" is %v bytes, buffer %v: %w", blockName, size, u.bo.GetSizeBytes(), ErrDataSize)
+	}
+	return p.BindUniformBlock(blockName, u.binding)
+}
+
+// Bind binds the buffer to its binding point again, e.g. after another buffer
+// was bound to it.
+func (u *UniformBuffer) Bind() {
+	u.bo.BindBufferBase(gl.UNIFORM_BUFFER, u.binding)
+}
+
+// GetBinding returns the binding point of the buffer.
+func (u *UniformBuffer) GetBinding() uint32 {
+	return u.binding
+}
+
+// GetBufferObject returns the underlying buffer object.
+func (u *UniformBuffer) GetBufferObject() *BufferObject {
+	return u.bo
+}
+
+// Destroy frees external resources.
+func (u *UniformBuffer) Destroy() {
+	u.bo.Destroy()
+}
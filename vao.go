@@ -1,6 +1,7 @@
 package gfx
 
 import (
+	"fmt"
 	"unsafe"
 
 	"github.com/go-gl/gl/v2.1/gl"
@@ -8,12 +9,32 @@ import (
 
 // VAO represents a Vertex Array Object.
 type VAO struct {
-	id         uint32
-	vbo        *BufferObject
-	mode       uint32
-	vertSize   int32
-	numAttribs uint32
-	layout     []int32
+	id   uint32
+	vbo  *BufferObject
+	mode uint32
+	// vbos holds the vertex buffers, the first of which is vbo, and strides
+	// the size of a vertex in each of them in bytes.
+	vbos    []*BufferObject
+	strides []int32
+	attribs []VertexAttrib
+	// attribOffsets holds the offset of each attribute within a vertex.
+	attribOffsets []int32
+	// sources holds, per buffer index, an external buffer set with
+	// SetVertexSource, or nil, and sourceOffsets the offset of the first
+	// vertex in it.
+	sources       []*BufferObject
+	sourceOffsets []uint32
+	// locations holds the attribute locations enabled when drawing, vertex
+	// attributes first.
+	locations  []uint32
+	ebo        *BufferObject
+	indexType  uint32
+	indexSize  int32
+	restart    bool
+	restartIdx uint32
+	// instance data, see SetInstanceLayout
+	instanceVBO  *BufferObject
+	instanceSize int32
 }
 
 // NewVAO creates the structure necessary for efficiently rendering shapes in
@@ -22,41 +43,78 @@ type VAO struct {
 // Example mode: gl.TRIANGLES.
 // Example vertex layout: (x,y,z, s,t) -> layout = (3, 2).
 func NewVAO(mode uint32, layout []int32) *VAO {
-	var id uint32
-	gl.GenVertexArrays(1, &id)
-	vbo := NewBufferObject()
-	var vertSize int32
-	for _, s := range layout {
-		vertSize += s
+	attribs := make([]VertexAttrib, len(layout))
+	for i, size := range layout {
+		attribs[i] = VertexAttrib{Location: uint32(i), Size: size}
 	}
-	configureVAO(id, vbo, layout, vertSize)
-	return &VAO{
-		id:         id,
-		vbo:        vbo,
-		mode:       mode,
-		vertSize:   vertSize,
-		numAttribs: uint32(len(layout)),
-		layout:     layout,
+	return newVAO(mode, attribs)
+}
+
+// newVAO creates a VAO with one buffer per buffer index of attribs.
+func newVAO(mode uint32, attribs []VertexAttrib) *VAO {
+	vao := &VAO{
+		mode:    mode,
+		attribs: attribs,
+	}
+	gl.GenVertexArrays(1, &vao.id)
+	numBuffers := 1
+	for _, a := range attribs {
+		if a.Buffer >= numBuffers {
+			numBuffers = a.Buffer + 1
+		}
 	}
+	vao.vbos = make([]*BufferObject, numBuffers)
+	for i := range vao.vbos {
+		vao.vbos[i] = NewBufferObject()
+	}
+	vao.sources = make([]*BufferObject, numBuffers)
+	vao.sourceOffsets = make([]uint32, numBuffers)
+	vao.vbo = vao.vbos[0]
+	vao.configure()
+	return vao
 }
 
-// configureVAO configures a VAO & VBO pair with a specified vertex layout.
-// Example vertex layout: (x,y,z, s,t) -> layout = (3, 2).
-func configureVAO(vaoID uint32, vbo *BufferObject, layout []int32, vertSize int32) {
-	vbo.Bind(gl.ARRAY_BUFFER)
-	gl.BindVertexArray(vaoID)
+// configure sets the attribute pointers of the VAO. Each buffer holds the
+// attributes assigned to it interleaved, in order.
+// Example vertex layout: (x,y,z, s,t) -> 5*4 = 20 bytes per vertex.
+func (vao *VAO) configure() {
+	vao.strides, vao.attribOffsets = attribLayout(vao.attribs, len(vao.vbos))
 
-	// calculate vertex size in bytes
-	// ex: (x,y,z,s,t) -> 5*4 = 20 bytes
-	vertexStride := vertSize * 4
-	var offset int32
-	for i := 0; i < len(layout); i++ {
-		gl.VertexAttribPointer(uint32(i), layout[i], gl.FLOAT, false, vertexStride, unsafe.Pointer(uintptr(offset*4)))
-		offset += layout[i]
+	gl.BindVertexArray(vao.id)
+	vao.locations = vao.locations[:0]
+	for i, a := range vao.attribs {
+		vao.attribPointer(i)
+		vao.locations = append(vao.locations, a.Location)
 	}
-
 	gl.BindVertexArray(0)
-	vbo.Unbind(gl.ARRAY_BUFFER)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// attribPointer points the i-th attribute at the buffer it is read from. The
+// VAO must be bound.
+func (vao *VAO) attribPointer(i int) {
+	a := vao.attribs[i]
+	bo, offset := vao.vertexBuffer(a.Buffer)
+	bo.Bind(gl.ARRAY_BUFFER)
+	xtype := uint32(a.Type)
+	if xtype == 0 {
+		xtype = gl.FLOAT
+	}
+	ptr := gl.PtrOffset(int(offset) + int(vao.attribOffsets[i]))
+	if a.Integer {
+		gl.VertexAttribIPointerEXT(a.Location, a.Size, xtype, vao.strides[a.Buffer], ptr)
+	} else {
+		gl.VertexAttribPointer(a.Location, a.Size, xtype, a.Normalized, vao.strides[a.Buffer], ptr)
+	}
+}
+
+// vertexBuffer returns the buffer the attributes of the given buffer index
+// are read from and the offset of the first vertex in it.
+func (vao *VAO) vertexBuffer(buffer int) (*BufferObject, uint32) {
+	if vao.sources[buffer] != nil {
+		return vao.sources[buffer], vao.sourceOffsets[buffer]
+	}
+	return vao.vbos[buffer], 0
 }
 
 // ErrEmptyData indiciates that the given data is empty.
@@ -68,23 +126,140 @@ func (vao *VAO) Load(data []float32, usage uint32) error {
 	if len(data) == 0 {
 		return ErrEmptyData
 	}
-	vao.vbo.BufferData(gl.ARRAY_BUFFER, uint32(4*len(data)), gl.Ptr(&data[0]), usage)
+	return vao.vbo.BufferData(gl.ARRAY_BUFFER, uint32(4*len(data)), gl.Ptr(&data[0]), usage)
+}
+
+// ErrIndexType indicates that indices are not of type []uint8, []uint16 or
+// []uint32.
+const ErrIndexType constErr = "indices must be []uint8, []uint16 or []uint32"
+
+// LoadIndices calls buffer data on the element buffer of the VAO, creating it
+// on first use. Indices must be a []uint8, []uint16 or []uint32. Once indices
+// are loaded, Draw renders them with glDrawElements.
+// Example usage: gl.STATIC_DRAW.
+//
+// Possible errors are ErrEmptyData and ErrIndexType.
+func (vao *VAO) LoadIndices(indices interface{}, usage uint32) error {
+	var ptr unsafe.Pointer
+	var count int
+	var indexType uint32
+	var indexSize int32
+	switch idx := indices.(type) {
+	case []uint8:
+		indexType, indexSize = gl.UNSIGNED_BYTE, 1
+		count, ptr = len(idx), slicePtr(idx)
+	case []uint16:
+		indexType, indexSize = gl.UNSIGNED_SHORT, 2
+		count, ptr = len(idx), slicePtr(idx)
+	case []uint32:
+		indexType, indexSize = gl.UNSIGNED_INT, 4
+		count, ptr = len(idx), slicePtr(idx)
+	default:
+		return fmt.Errorf("LoadIndices(%T): %w", indices, ErrIndexType)
+	}
+	if count == 0 {
+		return ErrEmptyData
+	}
+	vao.indexType, vao.indexSize = indexType, indexSize
+	if vao.ebo == nil {
+		vao.ebo = NewBufferObject()
+		// the element buffer binding is part of the VAO state
+		gl.BindVertexArray(vao.id)
+		vao.ebo.Bind(gl.ELEMENT_ARRAY_BUFFER)
+		gl.BindVertexArray(0)
+	}
+	gl.BindVertexArray(vao.id)
+	vao.ebo.sizeBytes = uint32(count) * uint32(vao.indexSize)
+	vao.ebo.usage = usage
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(vao.ebo.sizeBytes), ptr, usage)
+	gl.BindVertexArray(0)
 	return nil
 }
 
-// Draw renders the shapes from previously loaded data.
+// GetIndexCount returns the number of loaded indices.
+func (vao *VAO) GetIndexCount() int32 {
+	if vao.ebo == nil {
+		return 0
+	}
+	return int32(vao.ebo.GetSizeBytes()) / vao.indexSize
+}
+
+// GetVertexCount returns the number of loaded vertices, as determined by the
+// first buffer. For a buffer set with SetVertexSource, this is the number of
+// vertices from the offset to the end of the buffer.
+func (vao *VAO) GetVertexCount() int32 {
+	bo, offset := vao.vertexBuffer(0)
+	if vao.strides[0] == 0 || offset >= bo.GetSizeBytes() {
+		return 0
+	}
+	return int32(bo.GetSizeBytes()-offset) / vao.strides[0]
+}
+
+// glPrimitiveRestart is GL_PRIMITIVE_RESTART, which the v2.1 bindings lack.
+const glPrimitiveRestart = 0x8F9D
+
+// SetPrimitiveRestart enables or disables primitive restart when drawing
+// indices: an index equal to restartIndex ends the current primitive, e.g.
+// a triangle strip, and starts a new one. The maximum value of the index type
+// (e.g. 0xFFFF for []uint16) uses the fixed restart index of OpenGL 4.3;
+// other values require OpenGL 3.1 and NV_primitive_restart.
+func (vao *VAO) SetPrimitiveRestart(enable bool, restartIndex uint32) {
+	vao.restart = enable
+	vao.restartIdx = restartIndex
+}
+
+// enablePrimitiveRestart sets the global primitive restart state for drawing
+// the VAO and returns the capability to disable afterwards, if any.
+func (vao *VAO) enablePrimitiveRestart() uint32 {
+	if !vao.restart {
+		return 0
+	}
+	// shifting by 32 yields 0, so this is also correct for uint32 indices
+	maxIndex := uint32(1)<<(8*uint(vao.indexSize)) - 1
+	if vao.restartIdx == maxIndex {
+		gl.Enable(gl.PRIMITIVE_RESTART_FIXED_INDEX)
+		return gl.PRIMITIVE_RESTART_FIXED_INDEX
+	}
+	gl.Enable(glPrimitiveRestart)
+	gl.PrimitiveRestartIndexNV(vao.restartIdx)
+	return glPrimitiveRestart
+}
+
+// Draw renders the shapes from previously loaded data, using the loaded
+// indices if any.
 func (vao *VAO) Draw() {
-	if vao.vbo.GetSizeBytes() == 0 {
+	if vao.GetVertexCount() == 0 {
 		return
 	}
-	var i uint32
+	capability := vao.begin()
+	if count := vao.GetIndexCount(); count > 0 {
+		gl.DrawElements(vao.mode, count, vao.indexType, nil)
+	} else {
+		gl.DrawArrays(vao.mode, 0, vao.GetVertexCount())
+	}
+	vao.end(capability)
+}
+
+// begin binds the VAO and enables its attributes and primitive restart for
+// drawing, returning the capability end must disable.
+func (vao *VAO) begin() uint32 {
 	gl.BindVertexArray(vao.id)
-	for i = 0; i < vao.numAttribs; i++ {
-		gl.EnableVertexAttribArray(i)
+	for _, location := range vao.locations {
+		gl.EnableVertexAttribArray(location)
 	}
-	gl.DrawArrays(vao.mode, 0, int32(vao.vbo.GetSizeBytes())/(4*vao.vertSize))
-	for i = 0; i < vao.numAttribs; i++ {
-		gl.DisableVertexAttribArray(i)
+	if vao.GetIndexCount() > 0 {
+		return vao.enablePrimitiveRestart()
+	}
+	return 0
+}
+
+// end reverts the state set by begin.
+func (vao *VAO) end(capability uint32) {
+	if capability != 0 {
+		gl.Disable(capability)
+	}
+	for _, location := range vao.locations {
+		gl.DisableVertexAttribArray(location)
 	}
 	gl.BindVertexArray(0)
 }
@@ -92,7 +267,19 @@ func (vao *VAO) Draw() {
 // Destroy frees external resources.
 func (vao *VAO) Destroy() {
 	gl.DeleteVertexArrays(1, &vao.id)
-	vao.vbo.Destroy()
+	for _, vbo := range vao.vbos {
+		vbo.Destroy()
+	}
+	vao.vbos = nil
+	vao.sources = nil
+	if vao.ebo != nil {
+		vao.ebo.Destroy()
+		vao.ebo = nil
+	}
+	if vao.instanceVBO != nil {
+		vao.instanceVBO.Destroy()
+		vao.instanceVBO = nil
+	}
 	vao.mode = 0
 	vao.vbo = nil
 	vao.id = 0
@@ -0,0 +1,122 @@
+package gfx
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// MeshRange is a range of vertices, or of indices if the VAO has any, such as
+// one of several meshes packed into a VAO.
+type MeshRange struct {
+	First int32
+	Count int32
+	// BaseVertex is added to every index of an indexed range, so that the
+	// indices of each mesh can start at 0.
+	BaseVertex int32
+}
+
+// DrawArraysCommand is the command of an indirect draw without indices, as
+// read by glMultiDrawArraysIndirect. A buffer of commands is typically filled
+// by a compute shader, e.g. a GPU culling pass.
+type DrawArraysCommand struct {
+	Count         uint32
+	InstanceCount uint32
+	First         uint32
+	BaseInstance  uint32
+}
+
+// DrawElementsCommand is the command of an indirect draw with indices, as read
+// by glMultiDrawElementsIndirect.
+type DrawElementsCommand struct {
+	Count         uint32
+	InstanceCount uint32
+	FirstIndex    uint32
+	BaseVertex    int32
+	BaseInstance  uint32
+}
+
+// DrawRange renders count vertices starting at first, or count indices
+// starting at first if the VAO has indices.
+//
+// Possible errors are ErrOutOfBounds.
+func (vao *VAO) DrawRange(first, count int32) error {
+	return vao.MultiDraw([]MeshRange{{First: first, Count: count}})
+}
+
+// MultiDraw renders each of the given ranges with a single draw call.
+//
+// Possible errors are ErrOutOfBounds.
+func (vao *VAO) MultiDraw(ranges []MeshRange) error {
+	indexCount := vao.GetIndexCount()
+	total := indexCount
+	if indexCount == 0 {
+		total = vao.GetVertexCount()
+	}
+	for _, r := range ranges {
+		if r.First < 0 || r.Count < 0 || r.First+r.Count > total {
+			return fmt.Errorf("MultiDraw(%v): %w: %v", r, ErrOutOfBounds, total)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	counts := make([]int32, len(ranges))
+	for i, r := range ranges {
+		counts[i] = r.Count
+	}
+	capability := vao.begin()
+	if indexCount > 0 {
+		// byte offsets into the element buffer, kept as integers so that the
+		// garbage collector does not mistake them for pointers
+		offsets := make([]uintptr, len(ranges))
+		baseVertices := make([]int32, len(ranges))
+		for i, r := range ranges {
+			offsets[i] = uintptr(r.First * vao.indexSize)
+			baseVertices[i] = r.BaseVertex
+		}
+		gl.MultiDrawElementsBaseVertex(vao.mode, &counts[0], vao.indexType, (*unsafe.Pointer)(unsafe.Pointer(&offsets[0])), int32(len(ranges)), &baseVertices[0])
+	} else {
+		firsts := make([]int32, len(ranges))
+		for i, r := range ranges {
+			firsts[i] = r.First
+		}
+		gl.MultiDrawArrays(vao.mode, &firsts[0], &counts[0], int32(len(ranges)))
+	}
+	vao.end(capability)
+	return nil
+}
+
+// DrawIndirect renders drawCount draws whose commands are read by the GPU from
+// commands, starting at offset bytes. The commands are DrawElementsCommand
+// values if the VAO has indices, otherwise DrawArraysCommand values, tightly
+// packed, e.g. in a Buffer[DrawElementsCommand] or StorageBuffer written by a
+// compute shader.
+//
+// Indirect multi-draws require OpenGL 4.3.
+//
+// Possible errors are ErrOutOfBounds.
+func (vao *VAO) DrawIndirect(commands *BufferObject, offset uint32, drawCount int32) error {
+	size := uint32(unsafe.Sizeof(DrawArraysCommand{}))
+	if vao.GetIndexCount() > 0 {
+		size = uint32(unsafe.Sizeof(DrawElementsCommand{}))
+	}
+	if drawCount < 0 || offset%4 != 0 || offset+uint32(drawCount)*size > commands.GetSizeBytes() {
+		return fmt.Errorf("DrawIndirect(%v, %v): %w: %v", offset, drawCount, ErrOutOfBounds, commands.GetSizeBytes())
+	}
+	if drawCount == 0 {
+		return nil
+	}
+	commands.Bind(gl.DRAW_INDIRECT_BUFFER)
+	capability := vao.begin()
+	if vao.GetIndexCount() > 0 {
+		gl.MultiDrawElementsIndirect(vao.mode, vao.indexType, gl.PtrOffset(int(offset)), drawCount, 0)
+	} else {
+		gl.MultiDrawArraysIndirect(vao.mode, gl.PtrOffset(int(offset)), drawCount, 0)
+	}
+	vao.end(capability)
+	commands.Unbind(gl.DRAW_INDIRECT_BUFFER)
+	return nil
+}
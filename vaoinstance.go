@@ -0,0 +1,119 @@
+package gfx
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// ErrAttribSize indicates that an attribute size does not fit into vertex
+// attribute slots.
+const ErrAttribSize constErr = "invalid attribute size"
+
+// attribSlots splits an attribute of size floats into vertex attribute slots
+// of at most 4 components, e.g. a mat4 (16) into 4 columns of 4 and a mat3 (9)
+// into 3 columns of 3.
+func attribSlots(size int32) (slots, components int32, err error) {
+	switch {
+	case size >= 1 && size <= 4:
+		return 1, size, nil
+	case size == 9:
+		return 3, 3, nil
+	case size > 4 && size <= 16 && size%4 == 0:
+		return size / 4, 4, nil
+	}
+	return 0, 0, fmt.Errorf("%w: %v", ErrAttribSize, size)
+}
+
+// SetInstanceLayout configures per-instance attributes, read from a separate
+// buffer loaded with LoadInstances, that advance once every divisor instances
+// instead of once per vertex. Their locations follow the highest vertex
+// attribute location: with vertex layout (3, 2), the first instance attribute
+// is at location 2.
+// Attributes larger than 4 floats, such as a mat4 (16) or mat3 (9), span
+// consecutive locations, one per column.
+// Example instance layout: (model matrix, color) -> layout = (16, 4).
+//
+// Instancing requires OpenGL 3.3 or ARB_instanced_arrays.
+//
+// Possible errors are ErrAttribSize.
+func (vao *VAO) SetInstanceLayout(layout []int32, divisor uint32) error {
+	var instanceSize int32
+	for _, size := range layout {
+		if _, _, err := attribSlots(size); err != nil {
+			return fmt.Errorf("SetInstanceLayout(%v): %w", layout, err)
+		}
+		instanceSize += size
+	}
+	if vao.instanceVBO == nil {
+		vao.instanceVBO = NewBufferObject()
+	}
+	vao.instanceSize = instanceSize
+
+	var location uint32
+	for _, a := range vao.attribs {
+		if a.Location >= location {
+			location = a.Location + 1
+		}
+	}
+	// drop the locations of a previous instance layout
+	vao.locations = vao.locations[:len(vao.attribs)]
+
+	vao.instanceVBO.Bind(gl.ARRAY_BUFFER)
+	gl.BindVertexArray(vao.id)
+	stride := instanceSize * 4
+	var offset int32
+	for _, size := range layout {
+		// the size was checked above
+		slots, components, _ := attribSlots(size)
+		for j := int32(0); j < slots; j++ {
+			gl.VertexAttribPointer(location, components, gl.FLOAT, false, stride, gl.PtrOffset(int(offset*4)))
+			gl.VertexAttribDivisorARB(location, divisor)
+			vao.locations = append(vao.locations, location)
+			offset += components
+			location++
+		}
+	}
+	gl.BindVertexArray(0)
+	vao.instanceVBO.Unbind(gl.ARRAY_BUFFER)
+	return nil
+}
+
+// LoadInstances calls buffer data on the instance buffer, which holds the
+// attributes of each instance one after another as set by SetInstanceLayout.
+// Example usage: gl.DYNAMIC_DRAW.
+//
+// Possible errors are ErrEmptyData, ErrAttribSize if no instance layout is
+// set and ErrBufferImmutable.
+func (vao *VAO) LoadInstances(data []float32, usage uint32) error {
+	if vao.instanceVBO == nil {
+		return fmt.Errorf("LoadInstances: no instance layout: %w", ErrAttribSize)
+	}
+	if len(data) == 0 {
+		return ErrEmptyData
+	}
+	return vao.instanceVBO.BufferData(gl.ARRAY_BUFFER, uint32(4*len(data)), gl.Ptr(&data[0]), usage)
+}
+
+// GetInstanceCount returns the number of instances in the instance buffer.
+func (vao *VAO) GetInstanceCount() int32 {
+	if vao.instanceVBO == nil || vao.instanceSize == 0 {
+		return 0
+	}
+	return int32(vao.instanceVBO.GetSizeBytes()) / (4 * vao.instanceSize)
+}
+
+// DrawInstanced renders count instances of the shapes from previously loaded
+// data, using the loaded indices if any.
+func (vao *VAO) DrawInstanced(count int32) {
+	if vao.GetVertexCount() == 0 || count <= 0 {
+		return
+	}
+	capability := vao.begin()
+	if indices := vao.GetIndexCount(); indices > 0 {
+		gl.DrawElementsInstancedARB(vao.mode, indices, vao.indexType, nil, count)
+	} else {
+		gl.DrawArraysInstancedARB(vao.mode, 0, vao.GetVertexCount(), count)
+	}
+	vao.end(capability)
+}
@@ -0,0 +1,298 @@
+package gfx
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// AttribType is the component type of a vertex attribute in a buffer.
+type AttribType uint32
+
+// Component types of vertex attributes.
+const (
+	AttribFloat         AttribType = gl.FLOAT
+	AttribHalfFloat     AttribType = gl.HALF_FLOAT
+	AttribByte          AttribType = gl.BYTE
+	AttribUnsignedByte  AttribType = gl.UNSIGNED_BYTE
+	AttribShort         AttribType = gl.SHORT
+	AttribUnsignedShort AttribType = gl.UNSIGNED_SHORT
+	AttribInt           AttribType = gl.INT
+	AttribUnsignedInt   AttribType = gl.UNSIGNED_INT
+)
+
+// Size returns the size of a component in bytes.
+func (t AttribType) Size() int32 {
+	switch t {
+	case AttribByte, AttribUnsignedByte:
+		return 1
+	case AttribHalfFloat, AttribShort, AttribUnsignedShort:
+		return 2
+	}
+	return 4
+}
+
+// VertexAttrib describes a vertex attribute of a VAO created with
+// NewVAOLayout.
+type VertexAttrib struct {
+	// Location is the attribute location in the shader, as set with
+	// layout(location = N).
+	Location uint32
+	// Size is the number of components, from 1 to 4.
+	Size int32
+	// Type is the type of the components in the buffer. The zero value is
+	// AttribFloat.
+	Type AttribType
+	// Normalized maps integer components to [0, 1] for unsigned and [-1, 1]
+	// for signed types when read as floats, e.g. for 8-bit colors.
+	Normalized bool
+	// Integer passes integer components unconverted to an int, ivecN, uint or
+	// uvecN shader input.
+	Integer bool
+	// Buffer is the index of the buffer the attribute is read from. The
+	// attributes of each buffer are interleaved in order, so attributes in
+	// different buffers are not interleaved with each other.
+	Buffer int
+	// Offset is the offset of the attribute within a vertex in bytes. It is
+	// only used if the buffer has an explicit Stride.
+	Offset int32
+	// Stride is the size of a vertex of the buffer in bytes, e.g.
+	// unsafe.Sizeof of the vertex struct. If any attribute of a buffer sets
+	// it, the Offset of every attribute of the buffer is used as given and
+	// attributes without a Stride take that of the buffer. Otherwise the
+	// attributes are laid out in order as described by NewVAOLayout.
+	Stride int32
+}
+
+// dataSize returns the size of the components of the attribute in bytes.
+func (a VertexAttrib) dataSize() int32 {
+	t := a.Type
+	if t == 0 {
+		t = AttribFloat
+	}
+	return a.Size * t.Size()
+}
+
+// byteSize returns the size of the attribute in a vertex in bytes, rounded up
+// to a multiple of 4 so that every attribute is aligned.
+func (a VertexAttrib) byteSize() int32 {
+	return roundUpInt32(a.dataSize(), 4)
+}
+
+// attribLayout returns the size of a vertex in each of numBuffers buffers and
+// the offset of each attribute within a vertex of its buffer.
+func attribLayout(attribs []VertexAttrib, numBuffers int) (strides, offsets []int32) {
+	strides = make([]int32, numBuffers)
+	explicit := make([]bool, numBuffers)
+	for _, a := range attribs {
+		if a.Stride != 0 {
+			strides[a.Buffer] = a.Stride
+			explicit[a.Buffer] = true
+		}
+	}
+	offsets = make([]int32, len(attribs))
+	for i, a := range attribs {
+		if explicit[a.Buffer] {
+			offsets[i] = a.Offset
+			continue
+		}
+		offsets[i] = strides[a.Buffer]
+		strides[a.Buffer] += a.byteSize()
+	}
+	return strides, offsets
+}
+
+func roundUpInt32(n, multiple int32) int32 {
+	return (n + multiple - 1) / multiple * multiple
+}
+
+// ErrAttribType indicates an invalid attribute component type.
+const ErrAttribType constErr = "invalid attribute type"
+
+// ErrAttribLocation indicates that an attribute location is used twice.
+const ErrAttribLocation constErr = "duplicate attribute location"
+
+// ErrAttribBuffer indicates that a buffer index below the highest one used has
+// no attributes.
+const ErrAttribBuffer constErr = "buffer has no attributes"
+
+// ErrAttribOffset indicates that an attribute does not fit in a vertex with
+// the stride of its buffer, or that the attributes of a buffer disagree on its
+// stride.
+const ErrAttribOffset constErr = "attribute offset does not fit vertex stride"
+
+// ErrVertexSize indicates that the size of the vertices loaded into a buffer
+// does not match the stride of its attributes.
+const ErrVertexSize constErr = "vertex size does not match attribute layout"
+
+// NewVAOLayout creates a VAO like NewVAO, with a vertex buffer for each buffer
+// index used by attribs. Buffer indices must be contiguous from 0. Unless the
+// buffer has an explicit Stride, each attribute starts at a multiple of 4
+// bytes within a vertex, so e.g. a 3 component AttribUnsignedByte attribute is
+// followed by one byte of padding.
+// Example vertex layout with float positions and normalized byte colors in
+// one buffer and float normals in another:
+//
+//	[]VertexAttrib{
+//		{Location: 0, Size: 3},
+//		{Location: 1, Size: 4, Type: AttribUnsignedByte, Normalized: true},
+//		{Location: 2, Size: 3, Buffer: 1},
+//	}
+//
+// Set Offset and Stride to match a vertex struct that is packed differently:
+//
+//	type vertex struct {
+//		Pos   [3]float32
+//		UV    [2]uint16
+//		Color [3]uint8
+//		Flags uint8
+//	}
+//	var v vertex
+//	stride := int32(unsafe.Sizeof(v))
+//	[]VertexAttrib{
+//		{Location: 0, Size: 3, Stride: stride},
+//		{Location: 1, Size: 2, Type: AttribUnsignedShort, Normalized: true, Offset: int32(unsafe.Offsetof(v.UV))},
+//		{Location: 2, Size: 3, Type: AttribUnsignedByte, Normalized: true, Offset: int32(unsafe.Offsetof(v.Color))},
+//		{Location: 3, Size: 1, Type: AttribUnsignedByte, Integer: true, Offset: int32(unsafe.Offsetof(v.Flags))},
+//	}
+//
+// Possible errors are ErrAttribSize, ErrAttribType, ErrAttribLocation,
+// ErrAttribBuffer and ErrAttribOffset.
+func NewVAOLayout(mode uint32, attribs []VertexAttrib) (*VAO, error) {
+	used := make(map[uint32]bool)
+	buffers := make(map[int]bool)
+	for i, a := range attribs {
+		if a.Size < 1 || a.Size > 4 {
+			return nil, fmt.Errorf("attribute %v: %w: %v", i, ErrAttribSize, a.Size)
+		}
+		switch a.Type {
+		case 0, AttribFloat, AttribHalfFloat:
+			if a.Integer || a.Normalized {
+				return nil, fmt.Errorf("attribute %v: floats are not integers: %w", i, ErrAttribType)
+			}
+		case AttribByte, AttribUnsignedByte, AttribShort, AttribUnsignedShort, AttribInt, AttribUnsignedInt:
+			if a.Integer && a.Normalized {
+				return nil, fmt.Errorf("attribute %v: integer attributes are not normalized: %w", i, ErrAttribType)
+			}
+		default:
+			return nil, fmt.Errorf("attribute %v: %w: %#x", i, ErrAttribType, uint32(a.Type))
+		}
+		if a.Buffer < 0 {
+			return nil, fmt.Errorf("attribute %v: buffer %v: %w", i, a.Buffer, ErrOutOfBounds)
+		}
+		if used[a.Location] {
+			return nil, fmt.Errorf("attribute %v: %w: %v", i, ErrAttribLocation, a.Location)
+		}
+		used[a.Location] = true
+		buffers[a.Buffer] = true
+	}
+	for i := 0; i < len(buffers); i++ {
+		if !buffers[i] {
+			return nil, fmt.Errorf("buffer %v: %w", i, ErrAttribBuffer)
+		}
+	}
+	if err := checkAttribOffsets(attribs, len(buffers)); err != nil {
+		return nil, err
+	}
+	return newVAO(mode, attribs), nil
+}
+
+// checkAttribOffsets returns ErrAttribOffset if an attribute has a negative
+// offset or stride, sets an offset in a buffer without a stride, disagrees on
+// the stride of its buffer or does not fit in it.
+func checkAttribOffsets(attribs []VertexAttrib, numBuffers int) error {
+	strides := make([]int32, numBuffers)
+	for i, a := range attribs {
+		if a.Offset < 0 || a.Stride < 0 {
+			return fmt.Errorf("attribute %v: offset %v, stride %v: %w", i, a.Offset, a.Stride, ErrAttribOffset)
+		}
+		if a.Stride == 0 {
+			continue
+		}
+		if strides[a.Buffer] != 0 && strides[a.Buffer] != a.Stride {
+			return fmt.Errorf("attribute %v: stride %v, buffer %v has stride %v: %w", i, a.Stride, a.Buffer, strides[a.Buffer], ErrAttribOffset)
+		}
+		strides[a.Buffer] = a.Stride
+	}
+	for i, a := range attribs {
+		stride := strides[a.Buffer]
+		if stride == 0 && a.Offset != 0 {
+			return fmt.Errorf("attribute %v: offset %v without a stride: %w", i, a.Offset, ErrAttribOffset)
+		}
+		if stride != 0 && a.Offset+a.dataSize() > stride {
+			return fmt.Errorf("attribute %v: offset %v size %v, stride %v: %w", i, a.Offset, a.dataSize(), stride, ErrAttribOffset)
+		}
+	}
+	return nil
+}
+
+// LoadBuffer calls buffer data on the given vertex buffer with sizeBytes from
+// ptr, which hold vertices laid out as described by the attributes of the
+// buffer. The number of vertices drawn is determined by the first buffer.
+// Example usage: gl.STATIC_DRAW.
+//
+// Possible errors are ErrEmptyData, ErrOutOfBounds for an invalid buffer and
+// ErrBufferImmutable.
+func (vao *VAO) LoadBuffer(buffer int, ptr unsafe.Pointer, sizeBytes uint32, usage uint32) error {
+	if buffer < 0 || buffer >= len(vao.vbos) {
+		return fmt.Errorf("LoadBuffer(%v): %w: %v buffers", buffer, ErrOutOfBounds, len(vao.vbos))
+	}
+	if ptr == nil || sizeBytes == 0 {
+		return ErrEmptyData
+	}
+	return vao.vbos[buffer].BufferData(gl.ARRAY_BUFFER, sizeBytes, ptr, usage)
+}
+
+// LoadVertices calls buffer data on the given vertex buffer of vao with data,
+// typically a slice of structs matching the attributes of the buffer (see
+// VAO.LoadBuffer). The size of T must be the stride of the buffer, or divide
+// it for flat slices of components such as []float32, in which case data must
+// hold whole vertices.
+//
+// Possible errors are those of LoadBuffer and ErrVertexSize.
+func LoadVertices[T any](vao *VAO, buffer int, data []T, usage uint32) error {
+	size := elementSize[T]()
+	if buffer >= 0 && buffer < len(vao.strides) {
+		stride := int(vao.strides[buffer])
+		if size == 0 || stride == 0 || stride%size != 0 || len(data)*size%stride != 0 {
+			return fmt.Errorf("LoadVertices(%v): element size %v, stride %v: %w", buffer, size, stride, ErrVertexSize)
+		}
+	}
+	return vao.LoadBuffer(buffer, slicePtr(data), uint32(len(data)*size), usage)
+}
+
+// GetBufferCount returns the number of vertex buffers of the VAO.
+func (vao *VAO) GetBufferCount() int {
+	return len(vao.vbos)
+}
+
+// SetVertexSource reads the attributes of the given buffer index from bo,
+// starting at offset bytes, instead of from the VAO's own vertex buffer. This
+// draws vertices streamed into a shared buffer, such as an allocation returned
+// by RingBuffer.Write, without copying them. Offset must be a multiple of the
+// size of the components of the attributes, e.g. 4 for floats. A nil bo
+// reverts to the VAO's own buffer, which LoadBuffer and Load keep writing to.
+//
+// The VAO does not take ownership of bo.
+//
+// Possible errors are ErrOutOfBounds for an invalid buffer.
+func (vao *VAO) SetVertexSource(buffer int, bo *BufferObject, offset uint32) error {
+	if buffer < 0 || buffer >= len(vao.vbos) {
+		return fmt.Errorf("SetVertexSource(%v): %w: %v buffers", buffer, ErrOutOfBounds, len(vao.vbos))
+	}
+	if bo == nil {
+		offset = 0
+	}
+	vao.sources[buffer] = bo
+	vao.sourceOffsets[buffer] = offset
+	gl.BindVertexArray(vao.id)
+	for i, a := range vao.attribs {
+		if a.Buffer == buffer {
+			vao.attribPointer(i)
+		}
+	}
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return nil
+}
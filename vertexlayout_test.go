@@ -0,0 +1,142 @@
+package gfx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+func TestAttribLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		attribs    []VertexAttrib
+		numBuffers int
+		strides    []int32
+		offsets    []int32
+	}{
+		{
+			"packed",
+			[]VertexAttrib{{Size: 3}, {Size: 2}},
+			1, []int32{20}, []int32{0, 12},
+		},
+		{
+			"padded bytes",
+			[]VertexAttrib{{Size: 3, Type: AttribUnsignedByte}, {Size: 1, Type: AttribShort}},
+			1, []int32{8}, []int32{0, 4},
+		},
+		{
+			"explicit",
+			[]VertexAttrib{{Size: 3, Offset: 4, Stride: 16}, {Size: 3, Type: AttribUnsignedByte}},
+			1, []int32{16}, []int32{4, 0},
+		},
+		{
+			"explicit and computed buffers",
+			[]VertexAttrib{{Size: 2, Stride: 12}, {Size: 3, Buffer: 1}, {Size: 1, Buffer: 1}},
+			2, []int32{12, 16}, []int32{0, 0, 12},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strides, offsets := attribLayout(tt.attribs, tt.numBuffers)
+			if !reflect.DeepEqual(strides, tt.strides) {
+				t.Errorf("strides = %v, want %v", strides, tt.strides)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("offsets = %v, want %v", offsets, tt.offsets)
+			}
+		})
+	}
+}
+
+func TestCheckAttribOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		attribs []VertexAttrib
+		ok      bool
+	}{
+		{"computed", []VertexAttrib{{Size: 3}, {Size: 2}}, true},
+		{"explicit", []VertexAttrib{{Size: 3, Offset: 4, Stride: 16}, {Size: 4, Type: AttribUnsignedByte}}, true},
+		{"same stride", []VertexAttrib{{Size: 1, Stride: 8}, {Size: 1, Offset: 4, Stride: 8}}, true},
+		{"offset without stride", []VertexAttrib{{Size: 3}, {Size: 2, Offset: 12}}, false},
+		{"negative offset", []VertexAttrib{{Size: 1, Offset: -4, Stride: 8}}, false},
+		{"negative stride", []VertexAttrib{{Size: 1, Stride: -8}}, false},
+		{"different strides", []VertexAttrib{{Size: 1, Stride: 8}, {Size: 1, Stride: 12}}, false},
+		{"past stride", []VertexAttrib{{Size: 3, Offset: 8, Stride: 16}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAttribOffsets(tt.attribs, 1)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrAttribOffset) {
+				t.Errorf("err = %v, want ErrAttribOffset", err)
+			}
+		})
+	}
+}
+
+func TestLoadVerticesExplicitLayout(t *testing.T) {
+	// the color comes first, which the computed layout cannot express
+	type vertex struct {
+		Color [4]uint8
+		Pos   [3]float32
+	}
+	var v vertex
+	withContext(t, func() {
+		fb, err := NewFrameBuffer(4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fb.Destroy()
+		p := newTestProgram(t)
+		defer p.Destroy()
+		vao, err := NewVAOLayout(gl.TRIANGLES, []VertexAttrib{
+			{Location: 0, Size: 3, Offset: int32(unsafe.Offsetof(v.Pos)), Stride: int32(unsafe.Sizeof(v))},
+			{Location: 1, Size: 3, Type: AttribUnsignedByte, Normalized: true, Offset: int32(unsafe.Offsetof(v.Color))},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer vao.Destroy()
+
+		color := [4]uint8{0, 255, 255, 0}
+		vertices := []vertex{
+			{color, [3]float32{-1, -1, 0}},
+			{color, [3]float32{3, -1, 0}},
+			{color, [3]float32{-1, 3, 0}},
+		}
+		if err := LoadVertices(vao, 0, vertices, gl.STATIC_DRAW); err != nil {
+			t.Fatal(err)
+		}
+		got := drawPixel(t, fb, p, func() error { vao.Draw(); return nil })
+		if want := [4]byte{0, 255, 255, 255}; got != want {
+			t.Errorf("pixel = %v, want %v", got, want)
+		}
+		if code := gl.GetError(); code != gl.NO_ERROR {
+			t.Errorf("GL error 0x%X", code)
+		}
+	})
+}
+
+func TestLoadVerticesSize(t *testing.T) {
+	withContext(t, func() {
+		vao := NewVAO(gl.TRIANGLES, []int32{3, 3})
+		defer vao.Destroy()
+		if err := LoadVertices(vao, 0, make([]float32, 18), gl.STATIC_DRAW); err != nil {
+			t.Errorf("whole vertices of floats: %v", err)
+		}
+		if err := LoadVertices(vao, 0, make([]float32, 17), gl.STATIC_DRAW); !errors.Is(err, ErrVertexSize) {
+			t.Errorf("partial vertex: err = %v, want ErrVertexSize", err)
+		}
+		if err := LoadVertices(vao, 0, make([][5]float32, 3), gl.STATIC_DRAW); !errors.Is(err, ErrVertexSize) {
+			t.Errorf("short vertex struct: err = %v, want ErrVertexSize", err)
+		}
+		if err := LoadVertices(vao, 0, make([][6]float32, 3), gl.STATIC_DRAW); err != nil {
+			t.Errorf("vertex struct: %v", err)
+		}
+	})
+}
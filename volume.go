@@ -0,0 +1,236 @@
+package gfx
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// VoxelType is the data type of the single component of a voxel.
+type VoxelType int
+
+const (
+	// VoxelUint8 voxels are unsigned 8-bit integers, normalized to [0, 1].
+	VoxelUint8 VoxelType = iota
+	// VoxelUint16 voxels are unsigned 16-bit integers, normalized to [0, 1].
+	VoxelUint16
+	// VoxelFloat32 voxels are 32-bit floats.
+	VoxelFloat32
+)
+
+var voxelTypeNames = map[VoxelType]string{
+	VoxelUint8:   "uint8",
+	VoxelUint16:  "uint16",
+	VoxelFloat32: "float32",
+}
+
+// Size returns the size of a voxel in bytes.
+func (v VoxelType) Size() int32 {
+	switch v {
+	case VoxelUint16:
+		return 2
+	case VoxelFloat32:
+		return 4
+	}
+	return 1
+}
+
+// String returns the name of the voxel type, as used in volume headers.
+func (v VoxelType) String() string {
+	if name, ok := voxelTypeNames[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("VoxelType(%d)", int(v))
+}
+
+// ErrVoxelType indicates an unknown voxel type.
+const ErrVoxelType constErr = "unknown voxel type"
+
+// MarshalText implements encoding.TextMarshaler.
+func (v VoxelType) MarshalText() ([]byte, error) {
+	if name, ok := voxelTypeNames[v]; ok {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrVoxelType, int(v))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *VoxelType) UnmarshalText(text []byte) error {
+	for t, name := range voxelTypeNames {
+		if name == string(text) {
+			*v = t
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: \"%s\"", ErrVoxelType, text)
+}
+
+// glFormats returns the internal format, format and type used to store
+// voxels of this type.
+func (v VoxelType) glFormats() (internalFormat, format, xtype uint32) {
+	switch v {
+	case VoxelUint16:
+		return gl.R16, gl.RED, gl.UNSIGNED_SHORT
+	case VoxelFloat32:
+		return gl.R32F, gl.RED, gl.FLOAT
+	}
+	return gl.R8, gl.RED, gl.UNSIGNED_BYTE
+}
+
+// VolumeHeader describes raw volume data. It is stored as a JSON sidecar
+// file next to the raw data, for example:
+//
+//	{"width": 256, "height": 256, "depth": 128, "type": "uint16",
+//	 "spacing": [0.5, 0.5, 1.2], "bigEndian": false}
+type VolumeHeader struct {
+	Width  int32     `json:"width"`
+	Height int32     `json:"height"`
+	Depth  int32     `json:"depth"`
+	Type   VoxelType `json:"type"`
+	// Spacing is the size of a voxel along each axis, e.g. in millimeters.
+	// It defaults to 1 on each axis.
+	Spacing [3]float32 `json:"spacing"`
+	// BigEndian indicates multi-byte voxels are stored most significant byte
+	// first.
+	BigEndian bool `json:"bigEndian"`
+}
+
+// ReadVolumeHeader reads a VolumeHeader from the JSON file fileName.
+func ReadVolumeHeader(fileName string) (VolumeHeader, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return VolumeHeader{}, err
+	}
+	var header VolumeHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return VolumeHeader{}, fmt.Errorf("ReadVolumeHeader(\"%v\"): %w", fileName, err)
+	}
+	if header.Spacing == [3]float32{} {
+		header.Spacing = [3]float32{1, 1, 1}
+	}
+	return header, nil
+}
+
+// NewTexture3DFromVolume creates a single component Texture3D from raw voxels
+// described by header. Multi-byte voxels are converted to the host byte order
+// in place.
+func NewTexture3DFromVolume(header VolumeHeader, data []byte) (Texture3D, error) {
+	size := header.Type.Size()
+	want := int(header.Width) * int(header.Height) * int(header.Depth) * int(size)
+	if len(data) != want {
+		return Texture3D{}, fmt.Errorf("%vx%vx%v %v voxels in %v bytes, want %v: %w",
+			header.Width, header.Height, header.Depth, header.Type, len(data), want, ErrDataSize)
+	}
+	if size > 1 && header.BigEndian != hostBigEndian() {
+		swapBytes(data, int(size))
+	}
+	spacing := header.Spacing
+	if spacing == [3]float32{} {
+		spacing = [3]float32{1, 1, 1}
+	}
+	internalFormat, format, xtype := header.Type.glFormats()
+	t, err := newTexture3D(header.Width, header.Height, header.Depth, data, internalFormat, format, xtype, 1, size, spacing)
+	t.SetParameter(gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	t.SetParameter(gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	return t, err
+}
+
+// LoadVolume creates a Texture3D from the raw voxels in rawFileName, described
+// by the JSON header in headerFileName (see VolumeHeader).
+func LoadVolume(rawFileName, headerFileName string) (Texture3D, error) {
+	header, err := ReadVolumeHeader(headerFileName)
+	if err != nil {
+		return Texture3D{}, err
+	}
+	data, err := ioutil.ReadFile(rawFileName)
+	if err != nil {
+		return Texture3D{}, err
+	}
+	return NewTexture3DFromVolume(header, data)
+}
+
+// NewTexture3DFromSlices creates a single component Texture3D with one depth
+// slice per image, converted to grayscale. If every image is 16-bit
+// grayscale, the voxels are VoxelUint16, otherwise VoxelUint8.
+func NewTexture3DFromSlices(imgs []image.Image, spacing [3]float32) (Texture3D, error) {
+	if len(imgs) == 0 {
+		return Texture3D{}, ErrEmptyData
+	}
+	width := imgs[0].Bounds().Dx()
+	height := imgs[0].Bounds().Dy()
+	voxelType := VoxelUint16
+	for i, img := range imgs {
+		if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+			return Texture3D{}, fmt.Errorf("slice %v is %vx%v, want %vx%v: %w",
+				i, img.Bounds().Dx(), img.Bounds().Dy(), width, height, ErrLayerSize)
+		}
+		if img.ColorModel() != color.Gray16Model {
+			voxelType = VoxelUint8
+		}
+	}
+
+	data := make([]byte, 0, width*height*len(imgs)*int(voxelType.Size()))
+	for _, img := range imgs {
+		b := img.Bounds()
+		for j := b.Min.Y; j < b.Max.Y; j++ {
+			for i := b.Min.X; i < b.Max.X; i++ {
+				if voxelType == VoxelUint16 {
+					y := color.Gray16Model.Convert(img.At(i, j)).(color.Gray16).Y
+					// native byte order, as read by OpenGL
+					var voxel [2]byte
+					*(*uint16)(unsafe.Pointer(&voxel[0])) = y
+					data = append(data, voxel[:]...)
+				} else {
+					data = append(data, color.GrayModel.Convert(img.At(i, j)).(color.Gray).Y)
+				}
+			}
+		}
+	}
+	header := VolumeHeader{
+		Width:     int32(width),
+		Height:    int32(height),
+		Depth:     int32(len(imgs)),
+		Type:      voxelType,
+		Spacing:   spacing,
+		BigEndian: hostBigEndian(),
+	}
+	return NewTexture3DFromVolume(header, data)
+}
+
+// NewTexture3DFromSliceFiles creates a Texture3D like NewTexture3DFromSlices,
+// loading one slice from each of fileNames in order.
+//
+// To provide support for loading different image types, blank import the
+// respective image/* packages.
+func NewTexture3DFromSliceFiles(fileNames []string, spacing [3]float32) (Texture3D, error) {
+	imgs := make([]image.Image, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		img, err := loadImage(fileName)
+		if err != nil {
+			return Texture3D{}, err
+		}
+		imgs = append(imgs, img)
+	}
+	return NewTexture3DFromSlices(imgs, spacing)
+}
+
+// hostBigEndian reports whether the host stores the most significant byte
+// first.
+func hostBigEndian() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 0
+}
+
+// swapBytes reverses the byte order of each size byte element of data.
+func swapBytes(data []byte, size int) {
+	for i := 0; i+size <= len(data); i += size {
+		for a, b := i, i+size-1; a < b; a, b = a+1, b-1 {
+			data[a], data[b] = data[b], data[a]
+		}
+	}
+}